@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+)
+
+// defaultBenchTemplate is the 0200 sent when bench gets no -f.
+const defaultBenchTemplate = `{"mti": "0200", "3": "000000", "4": "000000001000", "7": "{{now}}", "11": "{{stan}}", "37": "{{rrn}}", "41": "BENCH001"}`
+
+var lengthHeaders = map[string]iso8583.LengthHeader{
+	"ascii4":  iso8583.LengthHeaderASCII4,
+	"ascii2":  iso8583.LengthHeaderASCII2,
+	"binary2": iso8583.LengthHeaderBinary2,
+	"binary4": iso8583.LengthHeaderBinary4,
+	"bcd":     iso8583.LengthHeaderBCD,
+	"none":    iso8583.LengthHeaderNone,
+}
+
+// benchResult collects the outcome of every request.
+type benchResult struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	codes     map[string]int
+	errors    map[string]int
+}
+
+func (r *benchResult) add(latency time.Duration, resp iso8583.ISO8583Object, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errors[err.Error()]++
+		return
+	}
+	r.latencies = append(r.latencies, latency)
+	r.codes[resp.GetField(39)]++
+}
+
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var spec specFlags
+	spec.register(fs)
+	addr := fs.String("addr", "", "host:port of the ISO 8583 host (required)")
+	conns := fs.Int("conns", 4, "number of connections")
+	tps := fs.Int("tps", 100, "target requests per second")
+	duration := fs.Duration("duration", 10*time.Second, "how long to send")
+	timeout := fs.Duration("timeout", 30*time.Second, "response timeout")
+	header := fs.String("header", "ascii4", "length header: ascii4, ascii2, binary2, binary4, bcd or none")
+	file := fs.String("f", "", "JSON or YAML request template; values may use {{stan}}, {{rrn}} and {{now}}")
+	_ = fs.Parse(args)
+
+	if *addr == "" {
+		return fmt.Errorf("-addr is required")
+	}
+	if *tps <= 0 || *conns <= 0 {
+		return fmt.Errorf("-tps and -conns must be positive")
+	}
+	lengthHeader, ok := lengthHeaders[*header]
+	if !ok {
+		return fmt.Errorf("unknown length header %q", *header)
+	}
+	pk, err := spec.packager()
+	if err != nil {
+		return err
+	}
+	data := []byte(defaultBenchTemplate)
+	if *file != "" {
+		if data, err = os.ReadFile(*file); err != nil {
+			return err
+		}
+	}
+	template, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+
+	pool := &iso8583.Pool{
+		Address:             *addr,
+		Size:                *conns,
+		Timeout:             *timeout,
+		KeyFields:           []int{11},
+		LengthHeader:        lengthHeader,
+		Packager:            pk,
+		HealthCheckInterval: time.Minute,
+	}
+	if err := pool.Connect(); err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	result := &benchResult{codes: map[string]int{}, errors: map[string]int{}}
+	var seq atomic.Uint64
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(time.Second / time.Duration(*tps))
+	defer ticker.Stop()
+	start := time.Now()
+	deadline := start.Add(*duration)
+	for now := range ticker.C {
+		if now.After(deadline) {
+			break
+		}
+		n := seq.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			iso := pk.NewMessage()
+			if err := setFields(iso, expandBench(template, n)); err != nil {
+				result.add(0, nil, err)
+				return
+			}
+			sent := time.Now()
+			resp, err := pool.Send(iso)
+			result.add(time.Since(sent), resp, err)
+		}()
+	}
+	wg.Wait()
+	result.print(time.Since(start), seq.Load())
+	return nil
+}
+
+// expandBench returns template with the placeholders filled for request n.
+func expandBench(template map[string]any, n uint64) map[string]any {
+	stan := fmt.Sprintf("%06d", (n-1)%999999+1)
+	rrn := fmt.Sprintf("%012d", n)
+	now := time.Now().UTC().Format("0102150405")
+	replacer := strings.NewReplacer("{{stan}}", stan, "{{rrn}}", rrn, "{{now}}", now)
+
+	fields := make(map[string]any, len(template))
+	for key, value := range template {
+		fields[key] = replacer.Replace(fmt.Sprint(value))
+	}
+	return fields
+}
+
+func (r *benchResult) print(elapsed time.Duration, sent uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ok := len(r.latencies)
+	fmt.Printf("requests:   %d sent, %d answered, %d failed in %s\n", sent, ok, int(sent)-ok, elapsed.Round(time.Millisecond))
+	fmt.Printf("throughput: %.1f responses/s\n", float64(ok)/elapsed.Seconds())
+	if ok > 0 {
+		sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+		pct := func(p float64) time.Duration {
+			return r.latencies[min(ok-1, int(float64(ok)*p))]
+		}
+		fmt.Printf("latency:    min %s  p50 %s  p90 %s  p99 %s  max %s\n",
+			r.latencies[0], pct(0.50), pct(0.90), pct(0.99), r.latencies[ok-1])
+	}
+	printCounts("response codes:", r.codes)
+	printCounts("errors:", r.errors)
+}
+
+func printCounts(title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Println(title)
+	for _, key := range keys {
+		label := key
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Printf("  %-10s %d\n", label, counts[key])
+	}
+}
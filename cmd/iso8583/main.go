@@ -0,0 +1,114 @@
+// Command iso8583 decodes, builds and checks ISO 8583 messages from the
+// command line, driven by a spec file or a built-in dialect:
+//
+//	iso8583 parse -spec isopackager.yml 0200723A...
+//	iso8583 parse -dialect visa-base1 -hex -mask < message.hex
+//	iso8583 compose -spec isopackager.yml -hex request.json
+//	iso8583 validate -spec isopackager.yml < message.txt
+//	iso8583 bench -spec isopackager.yml -addr switch:7001 -tps 200 -duration 1m
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+)
+
+const usage = `Usage: iso8583 <command> [flags] [input]
+
+Commands:
+  parse     decode a message and print its fields
+  compose   build a message from JSON or YAML fields
+  validate  parse a message and check it against the spec
+  bench     load test a host with templated 0200s
+
+The input is the argument, or stdin when there is none. Run
+"iso8583 <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	commands := map[string]func(args []string) error{
+		"parse":    runParse,
+		"compose":  runCompose,
+		"validate": runValidate,
+		"bench":    runBench,
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		if os.Args[1] != "-h" && os.Args[1] != "help" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		}
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "iso8583:", err)
+		os.Exit(1)
+	}
+}
+
+// specFlags are the flags selecting the spec, shared by every command.
+type specFlags struct {
+	spec    string
+	dialect string
+}
+
+func (s *specFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.spec, "spec", "", "spec file (YAML or JSON)")
+	fs.StringVar(&s.dialect, "dialect", "", "built-in dialect: "+strings.Join(iso8583.Dialects(), ", "))
+}
+
+// packager loads the selected spec, the ISO 8583:1987 dialect by default.
+func (s *specFlags) packager() (*iso8583.Packager, error) {
+	switch {
+	case s.spec != "" && s.dialect != "":
+		return nil, fmt.Errorf("-spec and -dialect are mutually exclusive")
+	case s.spec != "":
+		return iso8583.LoadSpec(s.spec)
+	case s.dialect != "":
+		return iso8583.NewPackager(s.dialect)
+	}
+	return iso8583.NewPackager(iso8583.DialectISO1987)
+}
+
+// readInput returns the first argument, or stdin when there is none, with
+// the trailing line break removed.
+func readInput(fs *flag.FlagSet) ([]byte, error) {
+	var data []byte
+	switch fs.NArg() {
+	case 0:
+		var err error
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, err
+		}
+	case 1:
+		data = []byte(fs.Arg(0))
+	default:
+		return nil, fmt.Errorf("expected one input, got %d", fs.NArg())
+	}
+	return bytes.TrimRight(data, "\r\n"), nil
+}
+
+// readMessage reads the wire message, decoding it from hex when asked to.
+// Whitespace in hex input is ignored so dumps can be pasted as they are.
+func readMessage(fs *flag.FlagSet, isHex bool) ([]byte, error) {
+	data, err := readInput(fs)
+	if err != nil || !isHex {
+		return data, err
+	}
+	message, err := hex.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex input: %w", err)
+	}
+	return message, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+)
+
+func runParse(args []string) error {
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	var spec specFlags
+	spec.register(fs)
+	isHex := fs.Bool("hex", false, "input is hex encoded")
+	mask := fs.Bool("mask", false, "mask sensitive fields (PAN, track data, PIN block)")
+	dump := fs.Bool("dump", false, "print an annotated hex dump instead, even of messages that do not parse")
+	asJSON := fs.Bool("json", false, "print the fields as JSON, the input format of compose")
+	_ = fs.Parse(args)
+
+	pk, err := spec.packager()
+	if err != nil {
+		return err
+	}
+	message, err := readMessage(fs, *isHex)
+	if err != nil {
+		return err
+	}
+	if *dump {
+		fmt.Print(pk.DumpHex(message))
+		return nil
+	}
+
+	iso := pk.NewMessage()
+	if err := iso.ParseBytes(message); err != nil {
+		return fmt.Errorf("%w (try -dump)", err)
+	}
+	if *mask {
+		iso = iso.Masked()
+	}
+	for _, w := range iso.Warnings() {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+	if *asJSON {
+		return printJSON(iso)
+	}
+	fmt.Print(iso.PrettyPrint())
+	return nil
+}
+
+// printJSON prints the MTI, header, trailer and fields of iso as a JSON
+// object keyed "mti", "header", "trailer" and the field numbers.
+func printJSON(iso iso8583.ISO8583Object) error {
+	fields := map[string]string{"mti": iso.GetMTI()}
+	if header := iso.GetHeader(); header != "" {
+		fields["header"] = header
+	}
+	if trailer := iso.GetTrailer(); trailer != "" {
+		fields["trailer"] = trailer
+	}
+	for _, field := range iso.Fields() {
+		// Field 0 adalah MTI, sudah ada di "mti"
+		if field > 1 {
+			fields[strconv.Itoa(field)] = iso.GetField(field)
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fields)
+}
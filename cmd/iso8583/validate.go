@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+)
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	var spec specFlags
+	spec.register(fs)
+	isHex := fs.Bool("hex", false, "input is hex encoded")
+	profile := fs.Bool("profile", false, "check the mandatory fields of the MTI profile too")
+	_ = fs.Parse(args)
+
+	pk, err := spec.packager()
+	if err != nil {
+		return err
+	}
+	message, err := readMessage(fs, *isHex)
+	if err != nil {
+		return err
+	}
+
+	iso := pk.NewMessage()
+	if err := iso.ParseWithOptions(message, iso8583.ParseOptions{Mode: iso8583.ParseStrict}); err != nil {
+		return err
+	}
+	err = iso.Validate()
+	if err == nil && *profile {
+		err = iso.ValidateProfile()
+	}
+	var verr *iso8583.ValidationError
+	if errors.As(err, &verr) {
+		for _, fe := range verr.Fields {
+			fmt.Fprintln(os.Stderr, fe)
+		}
+		return fmt.Errorf("%d field(s) invalid", len(verr.Fields))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("ok")
+	return nil
+}
@@ -6,11 +6,11 @@ toolchain go1.24.0
 
 require (
 	github.com/kpango/glg v1.6.15
-	github.com/randyardiansyah25/libpkg v1.2.5
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/goccy/go-json v0.10.2 // indirect
 	github.com/kpango/fastime v1.1.9 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
@@ -5,137 +5,506 @@
 package iso8583
 
 import (
-	"fmt"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
 	"net"
-	"strconv"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/randyardiansyah25/go-iso8583/logger"
-	"github.com/randyardiansyah25/libpkg/net/tcp"
-	strutils "github.com/randyardiansyah25/libpkg/util/str"
 )
 
-type TcpHandler func(iso ISO8583Object)
-
-var defaultHandler TcpHandler
+// TcpHandler handles one parsed request. Responses go through w; see
+// ResponseWriter for the default behavior when w is left untouched. ctx
+// expires Timeout seconds after the request was read, so downstream calls
+// can stop once the client has given up, and carries the ConnInfo of the
+// client, see ConnInfoFromContext.
+type TcpHandler func(ctx context.Context, w ResponseWriter, iso ISO8583Object)
 
 func GetEngine(readerTimeout int, fieldNumberKey ...int) *TCPIso8583Engine {
 	return &TCPIso8583Engine{
-		Timeout:         readerTimeout,
-		FieldNumber:     fieldNumberKey,
-		tcpHandlerGroup: make(map[string]TcpHandler),
+		Timeout:     readerTimeout,
+		FieldNumber: fieldNumberKey,
+		router:      NewRouter(),
 	}
 }
 
 type TCPIso8583Engine struct {
-	FieldNumber     []int
-	Timeout         int
-	tcpHandlerGroup map[string]TcpHandler
+	// FieldNumber are the fields whose values, concatenated, form the key
+	// AddHandler routes are matched against.
+	FieldNumber []int
+	// RoutingKey, when set, computes that key instead of FieldNumber.
+	RoutingKey RoutingKeyFunc
+	// RoutingFallback are keys tried in order when no AddHandler route was
+	// added for the first key, e.g. MTI + DE 3 falling back to the MTI
+	// alone.
+	RoutingFallback []RoutingKeyFunc
+
+	Timeout      int
+	LengthHeader LengthHeader
+	// MaxFrameSize is the largest message read; a longer length header
+	// drops the connection before anything is allocated. Defaults to
+	// DefaultMaxFrameSize.
+	MaxFrameSize int
+	// KeepAlive keeps the connection open after a response and keeps
+	// reading messages on it until the peer closes it or it stays idle for
+	// IdleTimeout. Messages on one connection are handled concurrently.
+	KeepAlive bool
+	// IdleTimeout closes keep-alive connections that send nothing for this
+	// long. Defaults to Timeout seconds.
+	IdleTimeout time.Duration
+	// ReadTimeout bounds reading one keep-alive message once its first byte
+	// arrived. Zero leaves the idle deadline in place.
+	ReadTimeout time.Duration
+	// WriteTimeout bounds writing one response. Zero means no deadline.
+	WriteTimeout time.Duration
+	// VerifyClient is called after the TLS handshake with the client
+	// certificate chain already verified per tls.Config. Returning an error
+	// rejects the connection.
+	VerifyClient func(state tls.ConnectionState) error
+	// PanicResponseCode is the DE 39 sent back when a handler panics, e.g.
+	// "96" (system malfunction). Empty sends nothing; the panic is logged
+	// either way.
+	PanicResponseCode string
+	// MaxConcurrentHandlers caps the requests handled at the same time;
+	// zero means no limit. OverloadPolicy decides whether excess work waits
+	// or is rejected.
+	MaxConcurrentHandlers int
+	OverloadPolicy        OverloadPolicy
+	// Limits, when set, may refuse connections and drop requests, e.g. a
+	// *SourceLimits.
+	Limits LimitPolicy
+	// Metrics, when set, counts the engine traffic.
+	Metrics *Metrics
+	// Tracer, when set, traces every inbound message.
+	Tracer Tracer
+	// MAC, when set, verifies the MAC of every request before routing and
+	// stamps the MAC on every response the engine composes.
+	MAC *MACConfig
+	// SwapTPDU swaps the source and destination of the TPDU header on
+	// every response still carrying the request TPDU, see TPDUHeader.
+	SwapTPDU bool
+	// EchoFields are copied from the request into every response the
+	// handler writes that lacks them, e.g. DefaultEchoFields. Handler
+	// values are never overwritten.
+	EchoFields []int
+	// Duplicates, when set, answers requests already seen within its window
+	// instead of routing them again.
+	Duplicates *DuplicateCheck
+	// WireLog, when set, records every request and response with the
+	// sensitive fields masked.
+	WireLog *WireLog
+	// Events, when set, receives every request and response, e.g. to stream
+	// them to Kafka or NATS.
+	Events EventHook
+	// Logger receives the engine logs with conn_id, mti and stan attributes
+	// where known. Defaults to slog.Default.
+	Logger *slog.Logger
+
+	router     *Router
+	rawMu      sync.RWMutex
+	rawRoutes  []rawRoute
+	middleware []Middleware
+
+	networkManagement *NetworkManagement
+	keyExchange       *KeyExchange
+
+	mu          sync.Mutex
+	listeners   map[net.Listener]struct{}
+	activeConns map[net.Conn]struct{}
+	handlerWG   sync.WaitGroup
+	inShutdown  atomic.Bool
+	connSeq     atomic.Uint64
+	sem         chan struct{}
+	semOnce     sync.Once
 }
 
 func (t *TCPIso8583Engine) RunInBackground(port string) error {
-	return t.listen(port, true)
+	return t.listen(port, nil, true)
 }
 
 func (t *TCPIso8583Engine) Run(port string) error {
-	return t.listen(port, false)
+	return t.listen(port, nil, false)
 }
 
-func (t *TCPIso8583Engine) listen(port string, doInBackground bool) (err error) {
-	listener, err := net.Listen("tcp", fmt.Sprint(":", port))
-	if err != nil {
-		return err
-	}
+// RunTLS is like Run but serves TLS. Set tlsConfig.ClientAuth (e.g.
+// tls.RequireAndVerifyClientCert with ClientCAs) for mutual TLS; additional
+// checks on the client certificate can be hooked in through VerifyClient.
+func (t *TCPIso8583Engine) RunTLS(port string, tlsConfig *tls.Config) error {
+	return t.listen(port, tlsConfig, false)
+}
 
-	go logger.Watcher()
+// RunTLSInBackground is like RunInBackground but serves TLS.
+func (t *TCPIso8583Engine) RunTLSInBackground(port string, tlsConfig *tls.Config) error {
+	return t.listen(port, tlsConfig, true)
+}
 
+func (t *TCPIso8583Engine) listen(port string, tlsConfig *tls.Config, doInBackground bool) error {
 	if doInBackground {
-		go acceptConnection(listener, t.tcpHandlerGroup, t.Timeout, t.FieldNumber)
-	} else {
-		acceptConnection(listener, t.tcpHandlerGroup, t.Timeout, t.FieldNumber)
+		return t.RunPortInBackground(PortConfig{Port: port, TLSConfig: tlsConfig})
 	}
-	return
+	return t.RunPorts(PortConfig{Port: port, TLSConfig: tlsConfig})
 }
 
+// AddHandler routes requests whose routing key, see FieldNumber and
+// RoutingKey, equals the concatenated key, replacing the handler of a key
+// already added. Prefer Router for matching on MTI and DE 3. It may be
+// called while the engine runs.
 func (t *TCPIso8583Engine) AddHandler(handler TcpHandler, key ...string) {
-	t.tcpHandlerGroup[strings.Join(key, "")] = handler
+	t.router.handleKey(strings.Join(key, ""), handler)
+}
+
+// RemoveHandler removes the handler AddHandler added for key and reports
+// whether there was one. Requests for key go to the default handler from
+// then on.
+func (t *TCPIso8583Engine) RemoveHandler(key ...string) bool {
+	return t.router.removeKey(strings.Join(key, ""))
 }
 
+// AddDefaultHandler sets the handler for requests no route matches.
 func (t *TCPIso8583Engine) AddDefaultHandler(handler TcpHandler) {
-	defaultHandler = handler
+	t.router.NotFound(handler)
 }
 
-func acceptConnection(listener net.Listener, handlerChain map[string]TcpHandler, timeout int, fieldNumber []int) {
+// Router returns the engine router, e.g. to add Route rules with MTI,
+// processing code and priority.
+func (t *TCPIso8583Engine) Router() *Router {
+	return t.router
+}
+
+func (t *TCPIso8583Engine) acceptConnection(listener net.Listener, port *PortConfig) error {
+	defer t.trackListener(listener, false)
 	for {
 		c, err := listener.Accept()
 		if err != nil {
-			//_ = glg.Error("New client rejected by : ", err.Error())
-			logger.Error("New client rejected by : ", err.Error())
+			if t.inShutdown.Load() {
+				return ErrEngineClosed
+			}
+			// Listener ditutup dari luar, mis. listener milik pemanggil Serve
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			t.log().Error("accept failed", "err", err)
+			continue
+		}
+		var limiter ConnLimiter
+		if t.Limits != nil {
+			var ok bool
+			if limiter, ok = t.Limits.OpenConn(c.RemoteAddr()); !ok {
+				t.log().Warn("connection rejected: connection limit reached", "remote_addr", c.RemoteAddr().String())
+				_ = c.Close()
+				continue
+			}
+		}
+		// Tanpa keep-alive satu koneksi = satu request, jadi slot diambil
+		// per koneksi; dengan keep-alive slot diambil per message
+		if !t.KeepAlive && !t.acquire() {
+			t.log().Warn("connection rejected: handler limit reached", "remote_addr", c.RemoteAddr().String())
+			_ = c.Close()
+			closeLimiter(limiter)
+			continue
+		}
+		if !t.trackConn(c, true) {
+			_ = c.Close()
+			closeLimiter(limiter)
+			if !t.KeepAlive {
+				t.release()
+			}
 			continue
 		}
-		to := time.Duration(time.Duration(timeout) * time.Second)
+		to := time.Duration(time.Duration(t.Timeout) * time.Second)
 		_ = c.SetReadDeadline(time.Now().Add(to))
-		go handler(c, handlerChain, fieldNumber)
+		go t.handler(c, limiter, port)
 	}
 }
 
-func handler(c net.Conn, handlerChain map[string]TcpHandler, fieldNumber []int) {
+func (t *TCPIso8583Engine) handler(c net.Conn, limiter ConnLimiter, port *PortConfig) {
 	defer func() {
 		_ = c.Close()
+		t.trackConn(c, false)
+		closeLimiter(limiter)
 	}()
-	message, err := tcp.BasicIOHandlerReader(c)
+	if !t.KeepAlive {
+		// Slot diambil acceptConnection, dilepas juga saat handshake gagal
+		defer t.release()
+	}
+
+	info, err := t.newConnInfo(c, port)
 	if err != nil {
-		//_ = glg.Error("read error : ", err.Error())
-		logger.Error("read error : ", err.Error())
+		t.log().Error("handshake failed", "remote_addr", c.RemoteAddr().String(), "err", err)
+		return
+	}
+	log := t.log().With("conn_id", info.ID)
+	t.Metrics.connOpened()
+	defer t.Metrics.connClosed()
+
+	writeMu := &sync.Mutex{}
+	if !t.KeepAlive {
+		message, err := t.newReader(c).ReadMessage()
+		if err != nil {
+			log.Error("read failed", "err", err)
+			return
+		}
+		t.handleMessage(c, writeMu, info, message)
+		return
+	}
+
+	// Keep-alive: baca message berikutnya di koneksi yang sama sampai
+	// koneksi ditutup atau idle melewati IdleTimeout
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
+	reader := t.newReader(c)
+	idle := t.idleTimeout()
+	for {
+		_ = c.SetReadDeadline(time.Now().Add(idle))
+		// Dicek setelah deadline dipasang supaya tidak menimpa
+		// interruptIdleReads dari Shutdown
+		if t.inShutdown.Load() {
+			return
+		}
+
+		err := reader.Wait()
+		if err == nil && t.ReadTimeout > 0 {
+			_ = c.SetReadDeadline(time.Now().Add(t.ReadTimeout))
+		}
+		var message []byte
+		if err == nil {
+			message, err = reader.ReadMessage()
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrDeadlineExceeded) && !t.inShutdown.Load() {
+				log.Error("read failed", "err", err)
+			}
+			return
+		}
+
+		if limiter != nil && !limiter.AllowMessage() {
+			log.Warn("request dropped: rate limit reached")
+			continue
+		}
+		if !t.acquire() {
+			log.Warn("request dropped: handler limit reached")
+			continue
+		}
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			defer t.release()
+			t.handleMessage(c, writeMu, info, message)
+		}()
+	}
+}
+
+func (t *TCPIso8583Engine) handleMessage(c io.Writer, writeMu *sync.Mutex, info *ConnInfo, message []byte) {
+	received := time.Now()
+	ctx, cancel := t.messageContext(info)
+	defer cancel()
+	t.Metrics.received()
+	if handler := t.rawHandler(message); handler != nil {
+		t.handleRaw(c, writeMu, info, message, handler)
 		return
 	}
 
-	iso, err := NewISO8583()
+	ctx, span := t.startSpan(ctx, "iso8583.message")
+	defer span.End()
+
+	log := t.log().With("conn_id", info.ID)
+	iso, err := info.port.newMessage()
 	if err != nil {
-		//_ = glg.Error("ISO 8583 parser error : ", err.Error())
-		logger.Error("ISO 8583 parser error : ", err.Error())
+		log.Error("create message failed", "err", err)
 		return
 	}
-	err = iso.Parse(message)
+	_, parseSpan := t.startSpan(ctx, "iso8583.parse")
+	err = iso.ParseBytes(message)
+	if err != nil {
+		parseSpan.RecordError(err)
+	}
+	parseSpan.End()
 	if err != nil {
-		//_ = glg.Error("ISO 8583 parser error : ", err.Error())
-		logger.Error("ISO 8583 parser error : ", err.Error())
+		span.RecordError(err)
+		t.Metrics.parseError()
+		t.logWire(Inbound, info, message, nil)
+		emitError(t.Events, Event{Direction: Inbound, ConnID: info.ID, Peer: info.RemoteAddr.String(), Raw: message}, err)
+		log.Error("parse failed", "err", err)
 		return
 	}
+	t.logWire(Inbound, info, message, iso)
+	emitInbound(t.Events, Event{ConnID: info.ID, Peer: info.RemoteAddr.String(), Raw: message, Message: iso})
 
-	var fieldValues []string
-	for _, field := range fieldNumber {
-		fieldVal := iso.GetField(field)
-		fieldValues = append(fieldValues, fieldVal)
+	log = log.With("mti", iso.GetMTI(), "stan", iso.GetField(11))
+	w := &responseWriter{
+		w:       c,
+		writeMu: writeMu,
+		header:  t.LengthHeader,
+		timeout: t.WriteTimeout,
+		metrics: t.Metrics,
+		span:    span,
+		log:     log,
+		onWrite: func(raw []byte) {
+			t.logWire(Outbound, info, raw, nil)
+			t.emitOutbound(info, raw, received)
+		},
+		onError: func(resp ISO8583Object, raw []byte, err error) {
+			emitError(t.Events, Event{Direction: Outbound, ConnID: info.ID, Peer: info.RemoteAddr.String(), Raw: raw, Message: resp, Latency: time.Since(received)}, err)
+		},
+		mac: t.MAC,
+	}
+	if t.SwapTPDU {
+		w.requestTPDU = iso.GetHeader()
+	}
+	if len(t.EchoFields) > 0 {
+		w.request, w.echoFields = iso, t.EchoFields
+	}
+	setMessageAttributes(span, iso)
+	defer t.recoverHandler(w, iso)
+	if t.MAC != nil && !t.MAC.exempt(iso) {
+		if err := VerifyMAC(iso, message, *t.MAC); err != nil {
+			log.Warn("request rejected: MAC verification failed", "err", err)
+			resp := NewResponseFrom(iso)
+			resp.SetField(39, t.MAC.failureCode())
+			t.writeDefaultResponse(ctx, w, resp)
+			return
+		}
+	}
+	if t.keyExchange != nil {
+		if handled, err := t.keyExchange.handle(iso); handled {
+			if err != nil {
+				log.Error("key exchange rejected", "err", err)
+			}
+			t.writeDefaultResponse(ctx, w, iso)
+			return
+		}
+	}
+	if t.networkManagement != nil && t.networkManagement.handle(iso) {
+		t.writeDefaultResponse(ctx, w, iso)
+		return
 	}
 
-	funct := handlerChain[strings.Join(fieldValues, "")]
+	if t.Duplicates != nil {
+		key, duplicate := t.checkDuplicate(ctx, w, iso)
+		if duplicate {
+			return
+		}
+		if key != "" {
+			onWrite, cache := w.onWrite, t.Duplicates.cache()
+			var answered atomic.Bool
+			w.onWrite = func(raw []byte) {
+				onWrite(raw)
+				cache.SetResponse(key, raw)
+				answered.Store(true)
+			}
+			// Tanpa respon (tidak ada route, Discard, panic, gagal tulis)
+			// retransmisi harus diproses lagi, bukan dibuang
+			defer func() {
+				if !answered.Load() {
+					cache.Remove(key)
+				}
+			}()
+		}
+	}
 
-	if funct != nil {
-		funct(iso)
-	} else {
+	funct := t.portRouter(info.port).lookup(iso, t.routingKeys(iso))
+	if funct == nil {
 		//iso.SetField(39, rc.ISOFailed)
 		//iso.SetField(48, "Not found")
-		//_ = glg.Error("Handle not found..")
-		if defaultHandler != nil {
-			defaultHandler(iso)
-		} else {
-			logger.Error("Handle not found..")
-			return
-		}
+		log.Error("handler not found")
+		return
+	}
+	start := time.Now()
+	handleCtx, handleSpan := t.startSpan(ctx, "iso8583.handle")
+	t.wrap(funct)(handleCtx, w, iso)
+	handleSpan.End()
+	t.Metrics.observe(start)
+	if w.handled() {
+		return
+	}
+	t.writeDefaultResponse(ctx, w, iso)
+}
+
+// routingKeys returns the AddHandler keys of iso in order of preference.
+func (t *TCPIso8583Engine) routingKeys(iso ISO8583Object) []string {
+	keys := make([]string, 0, 1+len(t.RoutingFallback))
+	if t.RoutingKey != nil {
+		keys = append(keys, t.RoutingKey(iso))
+	} else {
+		keys = append(keys, joinFields(iso, t.FieldNumber))
+	}
+	for _, fallback := range t.RoutingFallback {
+		keys = append(keys, fallback(iso))
+	}
+	return keys
+}
 
+func (t *TCPIso8583Engine) idleTimeout() time.Duration {
+	if t.IdleTimeout > 0 {
+		return t.IdleTimeout
 	}
-	resp, err := iso.ComposeMessage()
+	return time.Duration(t.Timeout) * time.Second
+}
+
+// messageContext returns the context for one request, carrying info and a
+// deadline of Timeout seconds when Timeout is set.
+func (t *TCPIso8583Engine) messageContext(info *ConnInfo) (context.Context, context.CancelFunc) {
+	ctx := context.WithValue(context.Background(), connInfoKey{}, info)
+	if t.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(t.Timeout)*time.Second)
+}
+
+// writeDefaultResponse composes iso and sends it back to the client.
+func (t *TCPIso8583Engine) writeDefaultResponse(ctx context.Context, w *responseWriter, iso ISO8583Object) {
+	_, span := t.startSpan(ctx, "iso8583.compose")
+	resp, err := w.compose(iso)
 	if err != nil {
-		//_ = glg.Error("ISO 8583 compose error : ", err.Error())
-		logger.Error("ISO 8583 compose error : ", err.Error())
+		span.RecordError(err)
+		span.End()
+		w.failed(iso, nil, err)
+		w.log.Error("compose failed", "err", err)
+		return
+	}
+	span.End()
+	t.Metrics.responseCode(iso)
+	w.responseCode(iso)
+
+	if err := w.WriteRaw(resp); err != nil {
+		w.log.Error("write failed", "err", err)
+	}
+}
+
+// logWire records a message exchanged with the client of info when WireLog
+// is set.
+func (t *TCPIso8583Engine) logWire(dir Direction, info *ConnInfo, raw []byte, iso ISO8583Object) {
+	if t.WireLog == nil {
 		return
 	}
+	if err := t.WireLog.Log(dir, info.RemoteAddr.String(), raw, iso); err != nil {
+		t.log().Error("wire log failed", "conn_id", info.ID, "err", err)
+	}
+}
 
-	ln := len(resp)
-	h := strconv.Itoa(ln)
-	resp = fmt.Sprint(strutils.LeftPad(h, 4, "0"), resp)
-	_, _ = c.Write([]byte(resp))
+// emitOutbound hands a response written to the client of info to Events,
+// parsed back for the hook, with the time since its request was received.
+func (t *TCPIso8583Engine) emitOutbound(info *ConnInfo, raw []byte, received time.Time) {
+	if t.Events == nil {
+		return
+	}
+	iso, err := info.port.newMessage()
+	if err == nil && iso.ParseBytes(raw) != nil {
+		iso = nil
+	}
+	emitOutbound(t.Events, Event{ConnID: info.ID, Peer: info.RemoteAddr.String(), Raw: raw, Message: iso, Latency: time.Since(received)})
+}
 
+// log returns Logger, or slog.Default when none is set.
+func (t *TCPIso8583Engine) log() *slog.Logger {
+	if t.Logger != nil {
+		return t.Logger
+	}
+	return slog.Default()
 }
@@ -0,0 +1,41 @@
+// Package audit persists the traffic of an engine or client for audit and
+// settlement reconciliation. Every message is stored masked, with its
+// timestamp, direction, connection and latency, and the fields used to
+// reconcile (STAN, RRN, terminal, processing code, amount, response code)
+// in their own columns:
+//
+//	db, _ := sql.Open("postgres", dsn)
+//	store := &audit.SQLStore{DB: db, Placeholder: audit.Dollar}
+//	if err := store.CreateTable(ctx); err != nil { ... }
+//	auditor := audit.New(store)
+//	defer auditor.Close()
+//	engine.Events = auditor
+//
+// Records are written in batches from a background goroutine, see
+// publish.Publisher, so a slow database never holds up a transaction.
+package audit
+
+import (
+	"context"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583/publish"
+)
+
+// Store persists batches of records.
+type Store interface {
+	Save(ctx context.Context, records []*publish.Record) error
+}
+
+// New creates an iso8583.EventHook saving every message to store.
+func New(store Store) *publish.Publisher {
+	return publish.New(sink{store})
+}
+
+// sink adapts a Store to publish.Sink.
+type sink struct {
+	store Store
+}
+
+func (s sink) Publish(ctx context.Context, records []*publish.Record) error {
+	return s.store.Save(ctx, records)
+}
@@ -0,0 +1,236 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+	"github.com/randyardiansyah25/go-iso8583/iso8583/publish"
+)
+
+// DefaultTable is the table used when SQLStore.Table is not set.
+const DefaultTable = "iso8583_audit"
+
+// columns are the audit table columns in insert order.
+var columns = []string{
+	"created_at", "type", "direction", "conn_id", "peer", "mti",
+	"stan", "rrn", "terminal_id", "processing_code", "amount", "response_code",
+	"latency_us", "length", "fields", "error",
+}
+
+var validTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Placeholder returns the bind parameter for the nth (1-based) argument of a
+// statement, which differs between drivers.
+type Placeholder func(n int) string
+
+// Question is the placeholder of MySQL and SQLite: ?.
+func Question(int) string { return "?" }
+
+// Dollar is the placeholder of PostgreSQL: $1, $2 and so on.
+func Dollar(n int) string { return "$" + strconv.Itoa(n) }
+
+// SQLStore is a Store keeping records in a database/sql table; CreateTable
+// creates it. Any driver works, given the right Placeholder.
+type SQLStore struct {
+	DB *sql.DB
+	// Table is the table name, optionally schema qualified. Defaults to
+	// DefaultTable.
+	Table string
+	// Placeholder defaults to Question.
+	Placeholder Placeholder
+}
+
+// Filter selects records for Find. Zero fields do not filter.
+type Filter struct {
+	// From and To bound created_at, From inclusive and To exclusive.
+	From, To   time.Time
+	Type       string
+	MTI        string
+	STAN       string
+	RRN        string
+	TerminalID string
+	// Limit caps the number of records returned.
+	Limit int
+}
+
+// Schema returns the statements creating the table and its indexes.
+func (s *SQLStore) Schema() ([]string, error) {
+	table, err := s.table()
+	if err != nil {
+		return nil, err
+	}
+	index := strings.ReplaceAll(table, ".", "_")
+	return []string{
+		`CREATE TABLE IF NOT EXISTS ` + table + ` (
+	created_at      TIMESTAMP NOT NULL,
+	type            VARCHAR(8) NOT NULL,
+	direction       VARCHAR(3) NOT NULL,
+	conn_id         BIGINT NOT NULL,
+	peer            VARCHAR(255) NOT NULL,
+	mti             VARCHAR(4) NOT NULL,
+	stan            VARCHAR(12) NOT NULL,
+	rrn             VARCHAR(12) NOT NULL,
+	terminal_id     VARCHAR(16) NOT NULL,
+	processing_code VARCHAR(6) NOT NULL,
+	amount          VARCHAR(12) NOT NULL,
+	response_code   VARCHAR(4) NOT NULL,
+	latency_us      BIGINT NOT NULL,
+	length          INTEGER NOT NULL,
+	fields          TEXT NOT NULL,
+	error           TEXT NOT NULL
+)`,
+		`CREATE INDEX IF NOT EXISTS ` + index + `_created_at ON ` + table + ` (created_at)`,
+		`CREATE INDEX IF NOT EXISTS ` + index + `_rrn ON ` + table + ` (rrn)`,
+		`CREATE INDEX IF NOT EXISTS ` + index + `_stan ON ` + table + ` (stan, created_at)`,
+	}, nil
+}
+
+// CreateTable runs Schema. Databases without CREATE INDEX IF NOT EXISTS,
+// such as MySQL, need the statements of Schema adapted and run by hand.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	statements, err := s.Schema()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := s.DB.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save implements Store, inserting records in one transaction.
+func (s *SQLStore) Save(ctx context.Context, records []*publish.Record) (er error) {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+	params := make([]string, len(columns))
+	for i := range params {
+		params[i] = s.placeholder(i + 1)
+	}
+	query := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(params, ", ") + ")"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if er != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, record := range records {
+		fields, err := json.Marshal(record.Fields)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.ExecContext(ctx,
+			record.Time.UTC(), record.Type, string(record.Direction), int64(record.ConnID), record.Peer, record.MTI,
+			record.Fields["11"], record.Fields["37"], record.Fields["41"], record.Fields["3"], record.Fields["4"], record.Fields["39"],
+			record.Latency.Microseconds(), record.Length, string(fields), record.Error,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Find returns the records matching f, oldest first.
+func (s *SQLStore) Find(ctx context.Context, f Filter) ([]*publish.Record, error) {
+	table, err := s.table()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, cond+" "+s.placeholder(len(args)))
+	}
+	if !f.From.IsZero() {
+		add("created_at >=", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		add("created_at <", f.To.UTC())
+	}
+	for _, c := range []struct{ column, value string }{
+		{"type", f.Type}, {"mti", f.MTI}, {"stan", f.STAN}, {"rrn", f.RRN}, {"terminal_id", f.TerminalID},
+	} {
+		if c.value != "" {
+			add(c.column+" =", c.value)
+		}
+	}
+
+	query := "SELECT created_at, type, direction, conn_id, peer, mti, latency_us, length, fields, error FROM " + table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at"
+	if f.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*publish.Record
+	for rows.Next() {
+		var (
+			record    publish.Record
+			direction string
+			connID    int64
+			latency   int64
+			fields    string
+		)
+		err := rows.Scan(&record.Time, &record.Type, &direction, &connID, &record.Peer, &record.MTI,
+			&latency, &record.Length, &fields, &record.Error)
+		if err != nil {
+			return nil, err
+		}
+		record.Direction = iso8583.Direction(direction)
+		record.ConnID = uint64(connID)
+		record.Latency = time.Duration(latency) * time.Microsecond
+		if err := json.Unmarshal([]byte(fields), &record.Fields); err != nil {
+			return nil, fmt.Errorf("audit record fields: %w", err)
+		}
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+func (s *SQLStore) table() (string, error) {
+	if s.Table == "" {
+		return DefaultTable, nil
+	}
+	if !validTable.MatchString(s.Table) {
+		return "", errors.New("audit: invalid table name " + strconv.Quote(s.Table))
+	}
+	return s.Table, nil
+}
+
+func (s *SQLStore) placeholder(n int) string {
+	if s.Placeholder != nil {
+		return s.Placeholder(n)
+	}
+	return Question(n)
+}
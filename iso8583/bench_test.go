@@ -0,0 +1,78 @@
+package iso8583
+
+import "testing"
+
+func benchmarkMessage(b *testing.B) ISO8583Object {
+	b.Helper()
+	iso := NewDefaultPackager().NewMessage()
+	iso.SetMTI("0200")
+	iso.SetField(2, "4111111111111111")
+	iso.SetField(3, "000000")
+	iso.SetField(4, "000000015000")
+	iso.SetField(7, "1017101010")
+	iso.SetField(11, "123456")
+	iso.SetField(12, "101010")
+	iso.SetField(13, "1017")
+	iso.SetField(37, "629010123456")
+	iso.SetField(41, "TERM0001")
+	iso.SetField(42, "MERCHANT0000001")
+	iso.SetField(43, "GO ISO8583 TEST MERCHANT       JAKARTA ID")
+	iso.SetField(48, "additional data for the benchmark")
+	iso.SetField(49, "360")
+	iso.SetField(102, "1234567890")
+	return iso
+}
+
+func BenchmarkCompose(b *testing.B) {
+	iso := benchmarkMessage(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := iso.ComposeBytes(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkComposeParallel(b *testing.B) {
+	iso := benchmarkMessage(b)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		msg := iso.Clone()
+		for pb.Next() {
+			if _, err := msg.ComposeBytes(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkParse(b *testing.B) {
+	iso := benchmarkMessage(b)
+	raw, err := iso.ComposeBytes()
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := iso.Clone()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	for i := 0; i < b.N; i++ {
+		if err := msg.ParseBytes(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRoundTrip(b *testing.B) {
+	iso := benchmarkMessage(b)
+	msg := iso.Clone()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		raw, err := iso.ComposeBytes()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := msg.ParseBytes(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
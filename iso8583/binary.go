@@ -0,0 +1,68 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// EncodingHex carries the raw field bytes as hex characters on the wire,
+// typically for ContentType "b" fields such as the DE 52 PIN block or the DE
+// 64/128 MAC. MaxLen and the LLVAR/LLLVAR prefix count raw bytes, so a fixed
+// field with MaxLen 8 takes 16 characters on the wire.
+const EncodingHex = "hex"
+
+// decodeValue converts the wire bytes of the field data to the stored value.
+// Binary fields are never charset converted.
+func (f FieldConfig) decodeValue(b []byte) (string, error) {
+	switch {
+	case f.Encoding == EncodingHex:
+		raw, err := hex.DecodeString(string(b))
+		return string(raw), err
+	case f.ContentType == "b":
+		return string(b), nil
+	default:
+		return f.decodeText(b), nil
+	}
+}
+
+// encodeValue converts the stored field value to wire bytes.
+func (f FieldConfig) encodeValue(v []byte) []byte {
+	switch {
+	case f.Encoding == EncodingHex:
+		return []byte(strings.ToUpper(hex.EncodeToString(v)))
+	case f.ContentType == "b":
+		return v
+	default:
+		return f.encodeText(v)
+	}
+}
+
+const hexUpper = "0123456789ABCDEF"
+
+// appendHexUpper appends the upper case hex form of s to dst.
+func appendHexUpper[T ~string | ~[]byte](dst []byte, s T) []byte {
+	for i := 0; i < len(s); i++ {
+		dst = append(dst, hexUpper[s[i]>>4], hexUpper[s[i]&0x0F])
+	}
+	return dst
+}
+
+// GetFieldBytes implements ISO8583Object. It returns the raw field bytes,
+// which for binary fields are the decoded bytes rather than their hex form.
+func (p *isoObject) GetFieldBytes(index int) []byte {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	value, ok := p.isoElement[index]
+	if !ok {
+		return nil
+	}
+	return []byte(value)
+}
+
+// SetFieldBytes implements ISO8583Object. It stores val as is, e.g. a PIN
+// block or MAC; encoding for the wire happens on compose.
+func (p *isoObject) SetFieldBytes(index int, val []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.isoElement[index] = string(val)
+}
@@ -0,0 +1,69 @@
+package iso8583
+
+import "encoding/hex"
+
+const (
+	// BitmapHex carries each 8-byte bitmap as 16 hex ASCII characters.
+	BitmapHex = "hex"
+	// BitmapBinary carries each bitmap as 8 raw bytes.
+	BitmapBinary = "binary"
+)
+
+// bitmapWidth returns the wire width of a single 64-bit bitmap.
+func (pk *Packager) bitmapWidth() int {
+	if pk.BitmapEncoding == BitmapBinary {
+		return 8
+	}
+	return 16
+}
+
+// readBitmap decodes the primary bitmap at pos, followed by the secondary
+// bitmap when bit 1 is set. It returns the raw bitmap and the new offset.
+func (pk *Packager) readBitmap(message []byte, pos int) ([]byte, int, error) {
+	bitmap := make([]byte, 0, 24)
+	for {
+		block, next, err := pk.readBitmapBlock(message, pos)
+		if err != nil {
+			return nil, pos, err
+		}
+		pos = next
+		bitmap = append(bitmap, block...)
+
+		if len(bitmap) == 16 || bitmap[len(bitmap)-8]&0x80 == 0 {
+			return bitmap, pos, nil
+		}
+	}
+}
+
+// readBitmapBlock decodes a single 64-bit bitmap at pos.
+func (pk *Packager) readBitmapBlock(message []byte, pos int) ([]byte, int, error) {
+	width := pk.bitmapWidth()
+	if pos+width > len(message) {
+		return nil, pos, errMessageTruncated
+	}
+	raw := message[pos : pos+width]
+	if pk.BitmapEncoding == BitmapBinary {
+		return append([]byte(nil), raw...), pos + width, nil
+	}
+	block, err := hex.DecodeString(string(raw))
+	return block, pos + width, err
+}
+
+// hasTertiaryBitmap reports whether the spec declares fields 129-192, in
+// which case DE 65 carries the tertiary bitmap.
+func (pk *Packager) hasTertiaryBitmap() bool {
+	for index := range pk.fields {
+		if index > 128 {
+			return true
+		}
+	}
+	return false
+}
+
+// appendBitmap writes bitmap to message using the spec's BitmapEncoding.
+func (pk *Packager) appendBitmap(message []byte, bitmap []byte) []byte {
+	if pk.BitmapEncoding == BitmapBinary {
+		return append(message, bitmap...)
+	}
+	return appendHexUpper(message, bitmap)
+}
@@ -0,0 +1,161 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Builder assembles a message field by field, checking every value against
+// the spec as it is set. Problems do not stop the chain; Build returns them
+// all at once:
+//
+//	iso, err := iso8583.NewBuilder(packager).
+//		MTI("0200").
+//		Field(2, pan).
+//		Field(3, "000000").
+//		Amount(4, 150000).
+//		Time(7, time.Now()).
+//		Build()
+type Builder struct {
+	packager *Packager
+	iso      ISO8583Object
+	problems []*FieldError
+}
+
+// NewBuilder starts a message for packager. A nil packager uses the spec
+// loaded by Load.
+func NewBuilder(packager *Packager) *Builder {
+	b := &Builder{packager: packager}
+	if packager == nil {
+		b.packager = defaultPackager.Load()
+	}
+	if b.packager == nil {
+		b.fail(0, errors.New("load iso 8583 spesification first"))
+		return b
+	}
+	b.packager = b.packager.active()
+	b.iso = b.packager.NewMessage()
+	return b
+}
+
+// MTI sets the message type indicator.
+func (b *Builder) MTI(mti string) *Builder {
+	if b.check(0, mti) {
+		b.iso.SetMTI(mti)
+	}
+	return b
+}
+
+// Field sets field index to the text form of val.
+func (b *Builder) Field(index int, val any) *Builder {
+	value := fmt.Sprint(val)
+	if b.check(index, value) {
+		b.iso.SetField(index, value)
+	}
+	return b
+}
+
+// Bytes sets field index to raw bytes, e.g. a PIN block.
+func (b *Builder) Bytes(index int, val []byte) *Builder {
+	if b.check(index, string(val)) {
+		b.iso.SetFieldBytes(index, val)
+	}
+	return b
+}
+
+// Amount sets an amount field from minor units, see SetAmount.
+func (b *Builder) Amount(index int, minorUnits int64) *Builder {
+	if b.iso == nil {
+		return b
+	}
+	if err := b.iso.SetAmount(index, minorUnits); err != nil {
+		b.problems = append(b.problems, asFieldError(index, err))
+	}
+	return b
+}
+
+// Time sets a date/time field (7, 12, 13, 14, 15, 16, 17 or 73) in the
+// layout and zone GetTime reads it with, see SetTime.
+func (b *Builder) Time(index int, t time.Time) *Builder {
+	if b.iso == nil {
+		return b
+	}
+	layout, ok := b.packager.timeLayout(index)
+	if !ok {
+		b.fail(index, errors.New("not a date/time field"))
+		return b
+	}
+	return b.Field(index, t.In(b.packager.zone(index)).Format(layout))
+}
+
+// SubField sets subfield sub of a composite field, see SetSubField.
+func (b *Builder) SubField(index, sub int, val any) *Builder {
+	if b.iso == nil {
+		return b
+	}
+	if err := b.iso.SetSubField(index, sub, val); err != nil {
+		b.problems = append(b.problems, asFieldError(index, err))
+	}
+	return b
+}
+
+// Build returns the message, or a *ValidationError listing every problem
+// found while building and by Validate.
+func (b *Builder) Build() (ISO8583Object, error) {
+	if b.iso != nil && b.iso.GetMTI() == "" && !b.failed(0) {
+		b.fail(0, errors.New("MTI not set"))
+	}
+	if len(b.problems) > 0 {
+		return nil, &ValidationError{Fields: b.problems}
+	}
+	if err := b.iso.Validate(); err != nil {
+		return nil, err
+	}
+	return b.iso, nil
+}
+
+// check reports whether value fits field index of the spec, recording the
+// problem when it does not.
+func (b *Builder) check(index int, value string) bool {
+	if b.iso == nil {
+		return false
+	}
+	fs, ok := b.packager.spec(index)
+	if !ok {
+		b.fail(index, errors.New("configuration missing"))
+		return false
+	}
+	if len(value) > fs.MaxLen && (fs.digits == 0 || !b.packager.TruncateOverLength) {
+		b.fail(index, fmt.Errorf("length %d exceeds MaxLen %d", len(value), fs.MaxLen))
+		return false
+	}
+	if err := fs.checkValue(value); err != nil && !b.packager.Lenient {
+		b.fail(index, err)
+		return false
+	}
+	return true
+}
+
+func (b *Builder) fail(index int, err error) {
+	b.problems = append(b.problems, &FieldError{Field: index, Err: err})
+}
+
+func (b *Builder) failed(index int) bool {
+	for _, p := range b.problems {
+		if p.Field == index {
+			return true
+		}
+	}
+	return false
+}
+
+// asFieldError wraps err as a problem of field index unless it already is
+// one.
+func asFieldError(index int, err error) *FieldError {
+	var ferr *FieldError
+	if errors.As(err, &ferr) {
+		return ferr
+	}
+	return &FieldError{Field: index, Err: err}
+}
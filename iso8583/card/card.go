@@ -0,0 +1,92 @@
+// Package card holds primary account number utilities: Luhn check digits,
+// BIN extraction, PAN validation and track 2 parsing.
+package card
+
+import "errors"
+
+var (
+	ErrPANLength     = errors.New("card: PAN must be 12 to 19 digits")
+	ErrPANCharacters = errors.New("card: PAN must contain digits only")
+	ErrCheckDigit    = errors.New("card: PAN check digit does not match (Luhn)")
+)
+
+// Luhn reports whether number ends with a valid Luhn check digit.
+func Luhn(number string) bool {
+	if len(number) < 2 || !digitsOnly(number) {
+		return false
+	}
+	return luhnSum(number, false)%10 == 0
+}
+
+// CheckDigit returns the Luhn check digit to append to partial, or 0 when
+// partial is not numeric.
+func CheckDigit(partial string) byte {
+	if !digitsOnly(partial) {
+		return 0
+	}
+	return byte('0' + (10-luhnSum(partial, true)%10)%10)
+}
+
+// luhnSum sums the digits of number from the right, doubling every other
+// digit starting with the rightmost one when double is set.
+func luhnSum(number string, double bool) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum
+}
+
+// ValidatePAN checks that pan is 12 to 19 digits with a valid Luhn check
+// digit.
+func ValidatePAN(pan string) error {
+	if !digitsOnly(pan) {
+		return ErrPANCharacters
+	}
+	if len(pan) < 12 || len(pan) > 19 {
+		return ErrPANLength
+	}
+	if !Luhn(pan) {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+// BIN returns the 6 digit bank identification number of pan, or "" when pan
+// is too short.
+func BIN(pan string) string {
+	return prefix(pan, 6)
+}
+
+// BIN8 returns the 8 digit issuer identification number used since the
+// ISO/IEC 7812 change to 8 digit BINs.
+func BIN8(pan string) string {
+	return prefix(pan, 8)
+}
+
+func prefix(pan string, n int) string {
+	if len(pan) < n {
+		return ""
+	}
+	return pan[:n]
+}
+
+func digitsOnly(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
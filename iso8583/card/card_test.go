@@ -0,0 +1,91 @@
+package card
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"79927398713", true},
+		{"79927398710", false},
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"00", true},
+		{"0", false},
+		{"", false},
+		{"4111 1111 1111 1111", false},
+		{"411111111111111A", false},
+	}
+	for _, tt := range tests {
+		if got := Luhn(tt.number); got != tt.want {
+			t.Errorf("Luhn(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		partial string
+		want    byte
+	}{
+		{"7992739871", '3'},
+		{"411111111111111", '1'},
+		{"12345678901", '5'},
+		{"0", '0'},
+		{"", 0},
+		{"41111X", 0},
+	}
+	for _, tt := range tests {
+		got := CheckDigit(tt.partial)
+		if got != tt.want {
+			t.Errorf("CheckDigit(%q) = %q, want %q", tt.partial, got, tt.want)
+		}
+		if got != 0 && !Luhn(tt.partial+string(got)) {
+			t.Errorf("%q with its check digit fails Luhn", tt.partial)
+		}
+	}
+}
+
+func TestValidatePAN(t *testing.T) {
+	tests := []struct {
+		pan  string
+		want error
+	}{
+		{"123456789015", nil},
+		{"4111111111111111", nil},
+		{"4000000000000000006", nil},
+		{"12345678901", ErrPANLength},
+		{"40000000000000000002", ErrPANLength},
+		{"4111111111111112", ErrCheckDigit},
+		{"4111-1111-1111-1111", ErrPANCharacters},
+		{"", ErrPANCharacters},
+	}
+	for _, tt := range tests {
+		if err := ValidatePAN(tt.pan); !errors.Is(err, tt.want) {
+			t.Errorf("ValidatePAN(%q) = %v, want %v", tt.pan, err, tt.want)
+		}
+	}
+}
+
+func TestBIN(t *testing.T) {
+	tests := []struct {
+		pan, bin, bin8 string
+	}{
+		{"4111111111111111", "411111", "41111111"},
+		{"5412345678", "541234", "54123456"},
+		{"5412345", "541234", ""},
+		{"54123", "", ""},
+	}
+	for _, tt := range tests {
+		if got := BIN(tt.pan); got != tt.bin {
+			t.Errorf("BIN(%q) = %q, want %q", tt.pan, got, tt.bin)
+		}
+		if got := BIN8(tt.pan); got != tt.bin8 {
+			t.Errorf("BIN8(%q) = %q, want %q", tt.pan, got, tt.bin8)
+		}
+	}
+}
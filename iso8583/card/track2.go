@@ -0,0 +1,65 @@
+package card
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrTrack2Separator = errors.New("card: track 2 has no field separator")
+	ErrTrack2Length    = errors.New("card: track 2 is too short for expiry and service code")
+	ErrTrack2Format    = errors.New("card: track 2 expiry and service code must be digits")
+)
+
+// Track2 is the track 2 equivalent data of DE 35: the PAN, the expiry date
+// (YYMM), the 3 digit service code and the issuer's discretionary data.
+type Track2 struct {
+	PAN           string
+	Expiry        string
+	ServiceCode   string
+	Discretionary string
+}
+
+// ParseTrack2 splits track 2 data. The start and end sentinels (';' and
+// '?') are accepted and dropped, and the field separator may be '=' (magnetic
+// stripe) or 'D' (chip and packed forms).
+func ParseTrack2(track string) (Track2, error) {
+	track = strings.TrimPrefix(track, ";")
+	track = strings.TrimSuffix(track, "?")
+
+	sep := strings.IndexAny(track, "=Dd")
+	if sep < 0 {
+		return Track2{}, ErrTrack2Separator
+	}
+	rest := track[sep+1:]
+	if len(rest) < 7 {
+		return Track2{}, ErrTrack2Length
+	}
+	if !digitsOnly(rest[:7]) {
+		return Track2{}, ErrTrack2Format
+	}
+	return Track2{
+		PAN:           track[:sep],
+		Expiry:        rest[:4],
+		ServiceCode:   rest[4:7],
+		Discretionary: rest[7:],
+	}, nil
+}
+
+// BuildTrack2 joins t with the '=' separator, without sentinels, after
+// checking the PAN with ValidatePAN and the expiry and service code
+// format.
+func BuildTrack2(t Track2) (string, error) {
+	if err := ValidatePAN(t.PAN); err != nil {
+		return "", err
+	}
+	if len(t.Expiry) != 4 || len(t.ServiceCode) != 3 || !digitsOnly(t.Expiry+t.ServiceCode) {
+		return "", ErrTrack2Format
+	}
+	return t.String(), nil
+}
+
+// String returns t joined with the '=' separator, unchecked.
+func (t Track2) String() string {
+	return t.PAN + "=" + t.Expiry + t.ServiceCode + t.Discretionary
+}
@@ -0,0 +1,54 @@
+package card
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTrack2(t *testing.T) {
+	want := Track2{PAN: "4111111111111111", Expiry: "2512", ServiceCode: "101", Discretionary: "123456789"}
+	tests := []struct {
+		name  string
+		track string
+		want  Track2
+		err   error
+	}{
+		{"equals separator", "4111111111111111=2512101123456789", want, nil},
+		{"D separator", "4111111111111111D2512101123456789", want, nil},
+		{"lower case d", "4111111111111111d2512101123456789", want, nil},
+		{"sentinels", ";4111111111111111=2512101123456789?", want, nil},
+		{"no discretionary data", "4111111111111111=2512101", Track2{PAN: "4111111111111111", Expiry: "2512", ServiceCode: "101"}, nil},
+		{"no separator", "41111111111111112512101", Track2{}, ErrTrack2Separator},
+		{"too short", "4111111111111111=251210", Track2{}, ErrTrack2Length},
+		{"non-digit expiry", "4111111111111111=25A2101", Track2{}, ErrTrack2Format},
+	}
+	for _, tt := range tests {
+		got, err := ParseTrack2(tt.track)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTrack2(t *testing.T) {
+	track := Track2{PAN: "4111111111111111", Expiry: "2512", ServiceCode: "101", Discretionary: "123456789"}
+	got, err := BuildTrack2(track)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "4111111111111111=2512101123456789" {
+		t.Fatalf("BuildTrack2 = %q", got)
+	}
+	if back, err := ParseTrack2(got); err != nil || back != track {
+		t.Fatalf("round trip: %+v, %v", back, err)
+	}
+
+	track.PAN = "4111111111111112"
+	if _, err := BuildTrack2(track); !errors.Is(err, ErrCheckDigit) {
+		t.Fatalf("bad PAN: got %v", err)
+	}
+}
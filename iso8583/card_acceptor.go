@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCardAcceptor is returned for a DE 43 value that is not 40 characters.
+var ErrCardAcceptor = errors.New("invalid card acceptor name/location")
+
+// DE 43 layout: name 25, city 13 and country code 2 characters, each left
+// justified and space filled.
+const (
+	cardAcceptorNameLen    = 25
+	cardAcceptorCityLen    = 13
+	cardAcceptorCountryLen = 2
+	cardAcceptorLen        = cardAcceptorNameLen + cardAcceptorCityLen + cardAcceptorCountryLen
+)
+
+// CardAcceptor is DE 43, the card acceptor name and location. Values are
+// kept without their space fill.
+type CardAcceptor struct {
+	Name string
+	City string
+	// Country is the ISO 3166 alpha-2 country code, e.g. "ID".
+	Country string
+}
+
+// ParseCardAcceptor parses the 40 character DE 43 value s.
+func ParseCardAcceptor(s string) (CardAcceptor, error) {
+	if len(s) != cardAcceptorLen {
+		return CardAcceptor{}, fmt.Errorf("%w: length %d, want %d", ErrCardAcceptor, len(s), cardAcceptorLen)
+	}
+	city := cardAcceptorNameLen + cardAcceptorCityLen
+	return CardAcceptor{
+		Name:    strings.TrimRight(s[:cardAcceptorNameLen], " "),
+		City:    strings.TrimRight(s[cardAcceptorNameLen:city], " "),
+		Country: strings.TrimRight(s[city:], " "),
+	}, nil
+}
+
+// String returns the 40 character DE 43 value of c. Each part is space
+// filled to its width; longer parts are cut, since merchant names often
+// exceed the 25 characters the field allows.
+func (c CardAcceptor) String() string {
+	return fitRight(c.Name, cardAcceptorNameLen) + fitRight(c.City, cardAcceptorCityLen) +
+		fitRight(c.Country, cardAcceptorCountryLen)
+}
+
+// GetCardAcceptor parses DE 43 of iso.
+func GetCardAcceptor(iso ISO8583Object) (CardAcceptor, error) {
+	c, err := ParseCardAcceptor(iso.GetField(43))
+	if err != nil {
+		return CardAcceptor{}, &FieldError{Field: 43, Err: err}
+	}
+	return c, nil
+}
+
+// SetCardAcceptor sets c as DE 43 of iso.
+func SetCardAcceptor(iso ISO8583Object, c CardAcceptor) {
+	iso.SetField(43, c.String())
+}
+
+// fitRight left justifies value in width characters, space filled or cut.
+func fitRight(value string, width int) string {
+	if len(value) >= width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
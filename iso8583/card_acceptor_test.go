@@ -0,0 +1,42 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCardAcceptorRoundTrip(t *testing.T) {
+	const de43 = "TOKO MAJU JAYA           JAKARTA      ID"
+	c, err := ParseCardAcceptor(de43)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CardAcceptor{Name: "TOKO MAJU JAYA", City: "JAKARTA", Country: "ID"}
+	if c != want {
+		t.Errorf("ParseCardAcceptor = %+v, want %+v", c, want)
+	}
+	if got := c.String(); got != de43 {
+		t.Errorf("String = %q, want %q", got, de43)
+	}
+
+	iso := NewDefaultPackager().NewMessage()
+	SetCardAcceptor(iso, want)
+	if got, err := GetCardAcceptor(iso); err != nil || got != want {
+		t.Errorf("GetCardAcceptor = %+v, %v, want %+v", got, err, want)
+	}
+}
+
+func TestCardAcceptorCutsLongParts(t *testing.T) {
+	c := CardAcceptor{Name: "A MERCHANT NAME LONGER THAN THE FIELD", City: "KOTA ADMINISTRASI", Country: "ID"}
+	got := c.String()
+	if len(got) != cardAcceptorLen {
+		t.Fatalf("len = %d, want %d", len(got), cardAcceptorLen)
+	}
+	if want := "A MERCHANT NAME LONGER THKOTA ADMINISTID"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+
+	if _, err := ParseCardAcceptor("TOKO"); !errors.Is(err, ErrCardAcceptor) {
+		t.Errorf("short DE 43: err = %v, want ErrCardAcceptor", err)
+	}
+}
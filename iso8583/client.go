@@ -0,0 +1,447 @@
+package iso8583
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrClientClosed is returned when sending on a client whose connection
+	// is closed.
+	ErrClientClosed = errors.New("iso8583 client is closed")
+	// ErrResponseTimeout is returned when no response arrives in time.
+	ErrResponseTimeout = errors.New("iso8583 response timeout")
+)
+
+// ISOClient keeps a persistent connection to a remote switch. Requests are
+// matched to their (possibly out of order) responses by the values of
+// KeyFields, DE 11 by default.
+type ISOClient struct {
+	Address string
+	Timeout time.Duration
+	// Timeouts overrides Timeout for the MTIs starting with a key, e.g.
+	// "08" for network management or "0320" for batch uploads. The longest
+	// matching prefix wins.
+	Timeouts     map[string]time.Duration
+	KeyFields    []int
+	LengthHeader LengthHeader
+	// MaxFrameSize is the largest message read; a longer length header
+	// drops the connection before anything is allocated. Defaults to
+	// DefaultMaxFrameSize.
+	MaxFrameSize int
+	// TLSConfig enables TLS when set. Provide Certificates for mutual TLS
+	// and VerifyConnection for extra checks on the host certificate.
+	TLSConfig *tls.Config
+	// Packager used to parse responses. When nil the spec loaded by Load is
+	// used.
+	Packager *Packager
+	// OnUnmatched receives responses that match no pending request, e.g.
+	// late responses or unsolicited messages from the host.
+	OnUnmatched func(iso ISO8583Object)
+	// OnTimeout is called with every request that got no response in time.
+	OnTimeout func(request ISO8583Object)
+	// OnLateResponse receives the response to a timed out request that
+	// arrives within LateResponseWindow (default 1m), instead of
+	// OnUnmatched.
+	OnLateResponse     func(request, response ISO8583Object)
+	LateResponseWindow time.Duration
+	// SendQueue, when above zero, queues up to that many outbound messages
+	// per priority and writes them from one goroutine, Priority messages
+	// first, so echo tests and sign-ons are not starved behind a burst of
+	// financial traffic. Priority defaults to network management (x8xx).
+	SendQueue int
+	Priority  func(iso ISO8583Object) bool
+	// AutoReversal sends the reversal of every authorization or financial
+	// request (not advice) that times out, built with ReversalOptions (e.g.
+	// ResponseCode "68") and sent with SendAdvice so the SAF keeps it while
+	// the host is down.
+	AutoReversal    bool
+	ReversalOptions ReversalOptions
+
+	// AutoReconnect redials after the connection drops, waiting
+	// ReconnectMinDelay (default 1s) and doubling the wait after every
+	// failed attempt up to ReconnectMaxDelay (default 1m).
+	AutoReconnect     bool
+	ReconnectMinDelay time.Duration
+	ReconnectMaxDelay time.Duration
+	// SignOn builds the message sent after every connect and reconnect,
+	// typically an 0800 with DE 70 001. A failed or declined sign-on fails
+	// the connect.
+	SignOn func() (ISO8583Object, error)
+	// SignOnAccept decides whether the sign-on response approves it. The
+	// default wants DE 39 "00" ("000" from ISO 8583:1993 on).
+	SignOnAccept func(resp ISO8583Object) bool
+	// OnStateChange is notified of every connection state change so the
+	// application can pause traffic while the link is down.
+	OnStateChange func(state ConnState, err error)
+	// Metrics, when set, counts the client traffic.
+	Metrics *Metrics
+	// WireLog, when set, records every request and response with the
+	// sensitive fields masked.
+	WireLog *WireLog
+	// Events, when set, receives every request and response, e.g. to stream
+	// them to Kafka or NATS.
+	Events EventHook
+	// Logger receives the client logs. Defaults to slog.Default.
+	Logger *slog.Logger
+	// SAF, when set, stores advices and reversals sent with SendAdvice
+	// while the host is down and forwards them once it is back.
+	SAF *SAF
+
+	conn    net.Conn
+	queue   *sendQueue
+	writeMu sync.Mutex
+
+	// state, lastEcho dan lastResponse untuk Health
+	state        atomic.Int32
+	lastEcho     atomic.Int64
+	lastResponse atomic.Int64
+
+	mu      sync.Mutex
+	pending map[string]*pendingRequest
+	late    map[string]lateRequest
+	err     error
+	closing bool
+	closeCh chan struct{}
+}
+
+// NewClient creates a client for address. timeout is the default response
+// timeout in seconds, keyFields default to DE 11 when omitted.
+func NewClient(address string, timeout int, keyFields ...int) *ISOClient {
+	if len(keyFields) == 0 {
+		keyFields = []int{11}
+	}
+	return &ISOClient{
+		Address:   address,
+		Timeout:   time.Duration(timeout) * time.Second,
+		KeyFields: keyFields,
+	}
+}
+
+// Connect dials the remote host, starts reading responses and sends the
+// SignOn message when configured.
+func (c *ISOClient) Connect() error {
+	c.mu.Lock()
+	c.closing = false
+	if c.closeCh == nil {
+		c.closeCh = make(chan struct{})
+	}
+	closeCh := c.closeCh
+	c.mu.Unlock()
+	c.startSAF(closeCh)
+
+	c.setState(StateConnecting, nil)
+	if err := c.connect(); err != nil {
+		c.setState(StateDisconnected, err)
+		return err
+	}
+	return nil
+}
+
+func (c *ISOClient) connect() error {
+	dialer := &net.Dialer{Timeout: c.Timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.Address, c.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", c.Address)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		_ = conn.Close()
+		return ErrClientClosed
+	}
+	for _, p := range c.pending {
+		close(p.ch)
+	}
+	var queue *sendQueue
+	if c.SendQueue > 0 {
+		queue = newSendQueue(c.SendQueue)
+		go queue.run(conn, c.LengthHeader)
+	}
+	c.conn = conn
+	c.queue = queue
+	c.pending = make(map[string]*pendingRequest)
+	c.err = nil
+	c.mu.Unlock()
+
+	go c.readLoop(conn, queue)
+
+	if err := c.signOn(); err != nil {
+		// Lepas conn dulu supaya readLoop tidak memicu reconnect kedua
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.mu.Unlock()
+		_ = conn.Close()
+		return err
+	}
+	c.setState(StateConnected, nil)
+	c.wakeSAF()
+	return nil
+}
+
+// Send writes iso and waits for its response using the Timeouts entry of
+// its MTI, or Timeout when none matches.
+func (c *ISOClient) Send(iso ISO8583Object) (ISO8583Object, error) {
+	return c.SendWithTimeout(iso, c.timeout(iso.GetMTI()))
+}
+
+// timeout returns the response timeout for mti.
+func (c *ISOClient) timeout(mti string) time.Duration {
+	timeout, longest := c.Timeout, -1
+	for prefix, d := range c.Timeouts {
+		if len(prefix) > longest && strings.HasPrefix(mti, prefix) {
+			timeout, longest = d, len(prefix)
+		}
+	}
+	return timeout
+}
+
+// SendWithTimeout writes iso and waits up to timeout for its response.
+func (c *ISOClient) SendWithTimeout(iso ISO8583Object, timeout time.Duration) (ISO8583Object, error) {
+	message, err := iso.ComposeBytes()
+	if err != nil {
+		emitError(c.Events, Event{Direction: Outbound, Peer: c.Address, Message: iso}, err)
+		return nil, err
+	}
+
+	key := c.key(iso)
+	respChan := make(chan ISO8583Object, 1)
+
+	c.mu.Lock()
+	if c.conn == nil || c.err != nil {
+		c.mu.Unlock()
+		return nil, c.closedErr()
+	}
+	if _, exists := c.pending[key]; exists {
+		c.mu.Unlock()
+		return nil, errors.New("a request with the same key is already pending")
+	}
+	c.pending[key] = &pendingRequest{ch: respChan, sent: time.Now()}
+	conn, queue := c.conn, c.queue
+	c.mu.Unlock()
+
+	start := time.Now()
+	if err := c.write(conn, queue, iso, message); err != nil {
+		c.removePending(key)
+		emitError(c.Events, Event{Direction: Outbound, Peer: c.Address, Raw: message, Message: iso}, err)
+		return nil, err
+	}
+	c.Metrics.sent()
+	c.logWire(Outbound, message, iso)
+	emitOutbound(c.Events, Event{Peer: c.Address, Raw: message, Message: iso})
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case resp, ok := <-respChan:
+		if !ok {
+			return nil, c.closedErr()
+		}
+		c.Metrics.observe(start)
+		c.Metrics.responseCode(resp)
+		if isEcho(iso) {
+			c.lastEcho.Store(time.Now().UnixNano())
+		}
+		return resp, nil
+	case <-timer.C:
+		c.Metrics.timeout()
+		emitError(c.Events, Event{Direction: Outbound, Peer: c.Address, Raw: message, Message: iso, Latency: time.Since(start)}, ErrResponseTimeout)
+		c.timedOut(key, iso)
+		return nil, ErrResponseTimeout
+	}
+}
+
+// Close closes the connection, fails every pending request and stops any
+// reconnect in progress.
+func (c *ISOClient) Close() error {
+	c.mu.Lock()
+	conn := c.conn
+	if c.err == nil {
+		c.err = ErrClientClosed
+	}
+	if !c.closing {
+		c.closing = true
+		if c.closeCh != nil {
+			close(c.closeCh)
+			c.closeCh = nil
+		}
+	}
+	c.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Connected reports whether the client holds a usable connection.
+func (c *ISOClient) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil && c.err == nil
+}
+
+func (c *ISOClient) readLoop(conn net.Conn, queue *sendQueue) {
+	c.Metrics.connOpened()
+	defer c.Metrics.connClosed()
+	if queue != nil {
+		// Koneksi putus: hentikan writer dan gagalkan antrian
+		defer queue.stop()
+	}
+
+	reader := NewMessageReader(conn, c.LengthHeader)
+	reader.MaxFrameSize = c.MaxFrameSize
+	for {
+		message, err := reader.ReadMessage()
+		if err != nil {
+			c.fail(conn, err)
+			return
+		}
+
+		iso, err := c.newMessage()
+		if err != nil {
+			c.fail(conn, err)
+			return
+		}
+		c.Metrics.received()
+		c.lastResponse.Store(time.Now().UnixNano())
+		if err := iso.ParseBytes(message); err != nil {
+			c.Metrics.parseError()
+			c.logWire(Inbound, message, nil)
+			emitError(c.Events, Event{Direction: Inbound, Peer: c.Address, Raw: message}, err)
+			c.log().Warn("response dropped: parse failed", "address", c.Address, "err", err)
+			continue
+		}
+
+		c.logWire(Inbound, message, iso)
+
+		key := c.key(iso)
+		c.mu.Lock()
+		request, late := c.takeLate(key, iso)
+		var (
+			p  *pendingRequest
+			ok bool
+		)
+		if !late {
+			p, ok = c.pending[key]
+			delete(c.pending, key)
+		}
+		c.mu.Unlock()
+
+		ev := Event{Peer: c.Address, Raw: message, Message: iso}
+		if ok {
+			ev.Latency = time.Since(p.sent)
+		}
+		emitInbound(c.Events, ev)
+
+		switch {
+		case late:
+			c.OnLateResponse(request, iso)
+		case ok:
+			p.ch <- iso
+		case c.OnUnmatched != nil:
+			c.OnUnmatched(iso)
+		}
+	}
+}
+
+// pendingRequest is a request waiting for its response.
+type pendingRequest struct {
+	ch   chan ISO8583Object
+	sent time.Time
+}
+
+func (c *ISOClient) newMessage() (ISO8583Object, error) {
+	if c.Packager != nil {
+		return c.Packager.NewMessage(), nil
+	}
+	return NewISO8583()
+}
+
+func (c *ISOClient) key(iso ISO8583Object) string {
+	var fieldValues []string
+	for _, field := range c.KeyFields {
+		fieldValues = append(fieldValues, iso.GetField(field))
+	}
+	return strings.Join(fieldValues, "")
+}
+
+func (c *ISOClient) removePending(key string) {
+	c.mu.Lock()
+	delete(c.pending, key)
+	c.mu.Unlock()
+}
+
+// fail records the connection error and releases every waiting request.
+// It does nothing when conn has already been replaced by a new Connect.
+func (c *ISOClient) fail(conn net.Conn, err error) {
+	_ = conn.Close()
+
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	if c.err == nil {
+		c.err = err
+	}
+	for key, p := range c.pending {
+		close(p.ch)
+		delete(c.pending, key)
+	}
+	closing, closeCh := c.closing, c.closeCh
+	c.mu.Unlock()
+
+	if closing {
+		return
+	}
+	c.log().Warn("connection lost", "address", c.Address, "err", err)
+	c.setState(StateDisconnected, err)
+	if c.AutoReconnect {
+		go c.reconnect(closeCh)
+	}
+}
+
+func (c *ISOClient) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil && !errors.Is(c.err, net.ErrClosed) {
+		return c.err
+	}
+	return ErrClientClosed
+}
+
+// log returns Logger, or slog.Default when none is set.
+func (c *ISOClient) log() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return slog.Default()
+}
+
+// logWire records a message exchanged with the host when WireLog is set.
+func (c *ISOClient) logWire(dir Direction, raw []byte, iso ISO8583Object) {
+	if c.WireLog == nil {
+		return
+	}
+	if err := c.WireLog.Log(dir, c.Address, raw, iso); err != nil {
+		c.log().Error("wire log failed", "address", c.Address, "err", err)
+	}
+}
@@ -0,0 +1,166 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FieldCodec encodes a field in a format the built-in encodings do not
+// cover, e.g. packed BCD, packed track 2, base64 blobs or proprietary TLVs.
+// The field value seen by the rest of the library is whatever Decode
+// returns and Encode accepts; MaxLen and the length passed around count
+// value bytes, not wire bytes.
+//
+// A codec is chosen per field with FieldConfig.Codec or per content type
+// with Packager.Codecs, by the name it was registered under with
+// RegisterCodec.
+type FieldCodec interface {
+	// EncodeLength returns the length prefix for a value of n bytes; nil
+	// for fixed fields.
+	EncodeLength(f FieldConfig, n int) ([]byte, error)
+	// Encode returns the wire form of value. Fixed fields are padded to
+	// MaxLen before Encode is called.
+	Encode(f FieldConfig, value []byte) ([]byte, error)
+	// DecodeLength reads the length prefix at the start of data and returns
+	// the value length and the number of prefix bytes read. Fixed fields
+	// return MaxLen and 0.
+	DecodeLength(f FieldConfig, data []byte) (length, read int, err error)
+	// Decode reads a value of length bytes at the start of data and returns
+	// it with the number of wire bytes read.
+	Decode(f FieldConfig, data []byte, length int) (value []byte, read int, err error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]FieldCodec{
+		CodecBCD: BCDCodec{},
+	}
+)
+
+// RegisterCodec makes codec available to specs under name. Register codecs
+// before loading the specs that use them, typically from an init function.
+// Registering a name twice replaces the previous codec.
+func RegisterCodec(name string, codec FieldCodec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[name] = codec
+}
+
+func lookupCodec(name string) (FieldCodec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	codec, ok := codecs[name]
+	return codec, ok
+}
+
+// resolveCodecs attaches the registered codec of every field that names
+// one, directly or through its content type.
+func (pk *Packager) resolveCodecs() error {
+	var problems []error
+	for index, field := range pk.fields {
+		name := field.Codec
+		if name == "" {
+			name = pk.Codecs[field.ContentType]
+		}
+		if name == "" {
+			continue
+		}
+		codec, ok := lookupCodec(name)
+		if !ok {
+			problems = append(problems, &FieldError{Field: index, Err: fmt.Errorf("unknown Codec %q", name)})
+			continue
+		}
+		field.codec = codec
+		pk.fields[index] = field
+	}
+	if len(problems) > 0 {
+		return &SpecError{Problems: problems}
+	}
+	return nil
+}
+
+// wireValue returns the wire form of value without its length prefix, for
+// locating a field inside a raw message.
+func (f FieldConfig) wireValue(value []byte) []byte {
+	if f.codec != nil {
+		if wire, err := f.codec.Encode(f, value); err == nil {
+			return wire
+		}
+	}
+	return f.encodeValue(value)
+}
+
+// CodecBCD is the name BCDCodec is registered under.
+const CodecBCD = "bcd"
+
+// BCDCodec packs numeric fields two digits per byte, as used by Visa and
+// many POS links. Odd length values get a leading zero nibble. The LLVAR and
+// LLLVAR prefixes are packed the same way in one and two bytes and count
+// digits. Hex digits are accepted too, so track 2 data packs with its 'D'
+// separator ('=' is packed as 'D').
+type BCDCodec struct{}
+
+var errBCD = errors.New("invalid BCD digit")
+
+// EncodeLength implements FieldCodec.
+func (BCDCodec) EncodeLength(f FieldConfig, n int) ([]byte, error) {
+	switch f.LenType {
+	case "llvar":
+		return hex.DecodeString(fmt.Sprintf("%02d", n))
+	case "lllvar":
+		return hex.DecodeString(fmt.Sprintf("%04d", n))
+	default:
+		return nil, nil
+	}
+}
+
+// Encode implements FieldCodec.
+func (BCDCodec) Encode(_ FieldConfig, value []byte) ([]byte, error) {
+	digits := strings.ReplaceAll(string(value), "=", "D")
+	if len(digits)%2 != 0 {
+		digits = "0" + digits
+	}
+	packed, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, errBCD
+	}
+	return packed, nil
+}
+
+// DecodeLength implements FieldCodec.
+func (BCDCodec) DecodeLength(f FieldConfig, data []byte) (int, int, error) {
+	size := 0
+	switch f.LenType {
+	case "llvar":
+		size = 1
+	case "lllvar":
+		size = 2
+	default:
+		return f.MaxLen, 0, nil
+	}
+	if len(data) < size {
+		return 0, 0, errMessageTruncated
+	}
+	length := 0
+	for _, c := range hex.EncodeToString(data[:size]) {
+		if c < '0' || c > '9' {
+			return 0, 0, errBCD
+		}
+		length = length*10 + int(c-'0')
+	}
+	return length, size, nil
+}
+
+// Decode implements FieldCodec.
+func (BCDCodec) Decode(_ FieldConfig, data []byte, length int) ([]byte, int, error) {
+	size := (length + 1) / 2
+	if len(data) < size {
+		return nil, 0, errMessageTruncated
+	}
+	digits := strings.ToUpper(hex.EncodeToString(data[:size]))
+	// Nibble pengisi di depan dibuang untuk panjang ganjil
+	return []byte(digits[len(digits)-length:]), size, nil
+}
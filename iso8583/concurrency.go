@@ -0,0 +1,50 @@
+package iso8583
+
+// OverloadPolicy decides what happens to new work once MaxConcurrentHandlers
+// is reached.
+type OverloadPolicy int
+
+const (
+	// OverloadQueue waits for a free slot. New connections stay in the
+	// listen backlog and keep-alive connections stop being read meanwhile.
+	OverloadQueue OverloadPolicy = iota
+	// OverloadReject closes new connections and drops requests read on
+	// keep-alive connections while all slots are taken.
+	OverloadReject
+)
+
+// acquire takes a handler slot, reporting false when the slot was rejected.
+// It always succeeds when MaxConcurrentHandlers is not set.
+func (t *TCPIso8583Engine) acquire() bool {
+	sem := t.semaphore()
+	if sem == nil {
+		return true
+	}
+	if t.OverloadPolicy == OverloadReject {
+		select {
+		case sem <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+	sem <- struct{}{}
+	return true
+}
+
+// release frees a slot taken by acquire.
+func (t *TCPIso8583Engine) release() {
+	if sem := t.semaphore(); sem != nil {
+		<-sem
+	}
+}
+
+func (t *TCPIso8583Engine) semaphore() chan struct{} {
+	if t.MaxConcurrentHandlers <= 0 {
+		return nil
+	}
+	t.semOnce.Do(func() {
+		t.sem = make(chan struct{}, t.MaxConcurrentHandlers)
+	})
+	return t.sem
+}
@@ -0,0 +1,51 @@
+package iso8583
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// ConnInfo describes the client connection a request arrived on.
+type ConnInfo struct {
+	// ID is unique per connection for the lifetime of the engine.
+	ID          uint64
+	RemoteAddr  net.Addr
+	LocalAddr   net.Addr
+	ConnectedAt time.Time
+	// TLS is the handshake state, nil for plain TCP.
+	TLS *tls.ConnectionState
+
+	// port tempat koneksi diterima, nil untuk Run biasa
+	port *PortConfig
+}
+
+type connInfoKey struct{}
+
+// ConnInfoFromContext returns the connection of the request handled with
+// ctx.
+func ConnInfoFromContext(ctx context.Context) (*ConnInfo, bool) {
+	info, ok := ctx.Value(connInfoKey{}).(*ConnInfo)
+	return info, ok
+}
+
+// newConnInfo describes c. TLS connections are handshaken first so the
+// handshake state is known before the first request.
+func (t *TCPIso8583Engine) newConnInfo(c net.Conn, port *PortConfig) (*ConnInfo, error) {
+	info := &ConnInfo{
+		port:        port,
+		ID:          t.connSeq.Add(1),
+		RemoteAddr:  c.RemoteAddr(),
+		LocalAddr:   c.LocalAddr(),
+		ConnectedAt: time.Now(),
+	}
+	if tc, ok := c.(*tls.Conn); ok {
+		if err := tc.Handshake(); err != nil {
+			return nil, err
+		}
+		state := tc.ConnectionState()
+		info.TLS = &state
+	}
+	return info, nil
+}
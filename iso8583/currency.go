@@ -0,0 +1,167 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownCurrency is returned for a currency code missing from the
+// currency table.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Currency is an ISO 4217 currency. Exponent is the number of minor unit
+// digits: 2 for USD (cents), 0 for JPY, 3 for KWD.
+type Currency struct {
+	Numeric  string
+	Alpha    string
+	Exponent int
+}
+
+// currencies holds the ISO 4217 currencies, keyed by numeric and by alpha
+// code, see RegisterCurrency.
+var currencies = map[string]Currency{}
+
+func init() {
+	for _, c := range []Currency{
+		{"036", "AUD", 2}, {"048", "BHD", 3}, {"096", "BND", 2}, {"108", "BIF", 0},
+		{"116", "KHR", 2}, {"124", "CAD", 2}, {"152", "CLP", 0}, {"156", "CNY", 2},
+		{"174", "KMF", 0}, {"208", "DKK", 2}, {"262", "DJF", 0}, {"324", "GNF", 0},
+		{"344", "HKD", 2}, {"352", "ISK", 0}, {"356", "INR", 2}, {"360", "IDR", 2},
+		{"368", "IQD", 3}, {"376", "ILS", 2}, {"392", "JPY", 0}, {"400", "JOD", 3},
+		{"410", "KRW", 0}, {"414", "KWD", 3}, {"418", "LAK", 2}, {"434", "LYD", 3},
+		{"458", "MYR", 2}, {"512", "OMR", 3}, {"554", "NZD", 2}, {"578", "NOK", 2},
+		{"586", "PKR", 2}, {"600", "PYG", 0}, {"608", "PHP", 2}, {"634", "QAR", 2},
+		{"643", "RUB", 2}, {"646", "RWF", 0}, {"682", "SAR", 2}, {"702", "SGD", 2},
+		{"704", "VND", 0}, {"710", "ZAR", 2}, {"752", "SEK", 2}, {"756", "CHF", 2},
+		{"764", "THB", 2}, {"784", "AED", 2}, {"788", "TND", 3}, {"800", "UGX", 0},
+		{"826", "GBP", 2}, {"840", "USD", 2}, {"901", "TWD", 2}, {"949", "TRY", 2},
+		{"950", "XAF", 0}, {"952", "XOF", 0}, {"953", "XPF", 0}, {"978", "EUR", 2},
+		{"986", "BRL", 2}, {"990", "CLF", 4},
+	} {
+		RegisterCurrency(c)
+	}
+}
+
+// RegisterCurrency adds or replaces a currency, e.g. one missing from the
+// built-in table or a network that settles IDR without minor units. Call it
+// during initialization; the table is not guarded for concurrent writes.
+func RegisterCurrency(c Currency) {
+	currencies[c.Numeric] = c
+	currencies[strings.ToUpper(c.Alpha)] = c
+}
+
+// LookupCurrency returns the currency with the numeric (DE 49, "840") or
+// alpha ("USD") code.
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(code)]
+	return c, ok
+}
+
+// amountCurrencyFields maps the amount fields to their currency field:
+// transaction, settlement and cardholder billing.
+var amountCurrencyFields = map[int]int{4: 49, 5: 50, 6: 51}
+
+// FormatMinorUnits returns minorUnits as a decimal with exponent fraction
+// digits, e.g. 150000 is "1500.00" with exponent 2 and "150000" with 0.
+func FormatMinorUnits(minorUnits int64, exponent int) string {
+	sign := ""
+	digits := fmt.Sprint(minorUnits)
+	if minorUnits < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if exponent <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// ParseMinorUnits converts the decimal amount to minor units, e.g. "1500.5"
+// to 150050 with exponent 2. More fraction digits than exponent is an error
+// rather than a silent rounding.
+func ParseMinorUnits(amount string, exponent int) (int64, error) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	if len(frac) > exponent {
+		return 0, fmt.Errorf("amount %q has more than %d decimals", amount, exponent)
+	}
+	digits := whole + frac + strings.Repeat("0", exponent-len(frac))
+	if whole == "" || !isDigits(digits) {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+	var n int64
+	for i := 0; i < len(digits); i++ {
+		if n > (1<<63-1-int64(digits[i]-'0'))/10 {
+			return 0, fmt.Errorf("amount %q overflows", amount)
+		}
+		n = n*10 + int64(digits[i]-'0')
+	}
+	return n, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNumeric(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDecimalAmount returns amount field index (4, 5 or 6) of iso as a
+// decimal in the currency of its currency field (49, 50 or 51), e.g.
+// "1500.00" for DE 4 "000000150000" with DE 49 "840" but "150000" with DE 49
+// "392" (JPY).
+func GetDecimalAmount(iso ISO8583Object, index int) (string, Currency, error) {
+	currency, err := amountCurrency(iso, index)
+	if err != nil {
+		return "", Currency{}, err
+	}
+	minorUnits, err := iso.GetAmount(index)
+	if err != nil {
+		return "", Currency{}, err
+	}
+	return FormatMinorUnits(minorUnits, currency.Exponent), currency, nil
+}
+
+// SetDecimalAmount sets amount field index (4, 5 or 6) of iso from the
+// decimal amount in currency, a numeric or alpha code, and sets the
+// matching currency field to its numeric code.
+func SetDecimalAmount(iso ISO8583Object, index int, amount, currency string) error {
+	currencyField, ok := amountCurrencyFields[index]
+	if !ok {
+		return &FieldError{Field: index, Err: errors.New("not an amount field with a currency field")}
+	}
+	c, ok := LookupCurrency(currency)
+	if !ok {
+		return &FieldError{Field: currencyField, Err: fmt.Errorf("%w %q", ErrUnknownCurrency, currency)}
+	}
+	minorUnits, err := ParseMinorUnits(amount, c.Exponent)
+	if err != nil {
+		return &FieldError{Field: index, Err: err}
+	}
+	if err := iso.SetAmount(index, minorUnits); err != nil {
+		return err
+	}
+	iso.SetField(currencyField, c.Numeric)
+	return nil
+}
+
+// amountCurrency returns the currency of amount field index.
+func amountCurrency(iso ISO8583Object, index int) (Currency, error) {
+	currencyField, ok := amountCurrencyFields[index]
+	if !ok {
+		return Currency{}, &FieldError{Field: index, Err: errors.New("not an amount field with a currency field")}
+	}
+	code := iso.GetField(currencyField)
+	if code == "" {
+		return Currency{}, &FieldError{Field: currencyField, Err: errors.New("currency code not set")}
+	}
+	c, ok := LookupCurrency(code)
+	if !ok {
+		return Currency{}, &FieldError{Field: currencyField, Err: fmt.Errorf("%w %q", ErrUnknownCurrency, code)}
+	}
+	return c, nil
+}
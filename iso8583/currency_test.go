@@ -0,0 +1,124 @@
+package iso8583
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatMinorUnits(t *testing.T) {
+	tests := []struct {
+		minorUnits int64
+		exponent   int
+		want       string
+	}{
+		{150000, 0, "150000"}, // JPY
+		{150000, 2, "1500.00"},
+		{5, 2, "0.05"},
+		{-150050, 2, "-1500.50"},
+		{1500250, 3, "1500.250"}, // KWD
+		{7, 3, "0.007"},
+		{123456789, 4, "12345.6789"}, // CLF
+		{0, 4, "0.0000"},
+	}
+	for _, tt := range tests {
+		if got := FormatMinorUnits(tt.minorUnits, tt.exponent); got != tt.want {
+			t.Errorf("FormatMinorUnits(%d, %d) = %q, want %q", tt.minorUnits, tt.exponent, got, tt.want)
+		}
+	}
+}
+
+func TestParseMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		exponent int
+		want     int64
+	}{
+		{"150000", 0, 150000},
+		{"1500.5", 2, 150050},
+		{"1500", 2, 150000},
+		{"0.007", 3, 7},
+		{"12345.6789", 4, 123456789},
+		{"9223372036854775807", 0, 1<<63 - 1},
+	}
+	for _, tt := range tests {
+		got, err := ParseMinorUnits(tt.amount, tt.exponent)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseMinorUnits(%q, %d) = %d, %v, want %d", tt.amount, tt.exponent, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseMinorUnitsRejects(t *testing.T) {
+	tests := []struct {
+		amount   string
+		exponent int
+		want     string
+	}{
+		{"1500.005", 2, "more than 2 decimals"},
+		{"1500.5", 0, "more than 0 decimals"},
+		{"9223372036854775808", 0, "overflows"},
+		{"92233720368547758.08", 2, "overflows"},
+		{".50", 2, "invalid amount"},
+		{"", 2, "invalid amount"},
+		{"-1.00", 2, "invalid amount"},
+		{"1,500.00", 2, "invalid amount"},
+	}
+	for _, tt := range tests {
+		_, err := ParseMinorUnits(tt.amount, tt.exponent)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseMinorUnits(%q, %d) error = %v, want %q", tt.amount, tt.exponent, err, tt.want)
+		}
+	}
+}
+
+func TestDecimalAmountRoundTrip(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		numeric  string
+		de4      string
+	}{
+		{"1500.50", "USD", "840", "000000150050"},
+		{"150000", "392", "392", "000000150000"},
+		{"12.345", "kwd", "414", "000000012345"},
+		{"1.2345", "CLF", "990", "000000012345"},
+	}
+	for _, tt := range tests {
+		iso := NewDefaultPackager().NewMessage()
+		if err := SetDecimalAmount(iso, 4, tt.amount, tt.currency); err != nil {
+			t.Fatalf("SetDecimalAmount(%q, %q): %v", tt.amount, tt.currency, err)
+		}
+		if got := iso.GetField(4); got != tt.de4 {
+			t.Errorf("%s %s: DE 4 = %q, want %q", tt.currency, tt.amount, got, tt.de4)
+		}
+		if got := iso.GetField(49); got != tt.numeric {
+			t.Errorf("%s %s: DE 49 = %q, want %q", tt.currency, tt.amount, got, tt.numeric)
+		}
+
+		amount, currency, err := GetDecimalAmount(iso, 4)
+		if err != nil {
+			t.Fatalf("GetDecimalAmount: %v", err)
+		}
+		if amount != tt.amount || currency.Numeric != tt.numeric {
+			t.Errorf("GetDecimalAmount = %q, %s, want %q, %s", amount, currency.Numeric, tt.amount, tt.numeric)
+		}
+	}
+}
+
+func TestDecimalAmountRejects(t *testing.T) {
+	iso := NewDefaultPackager().NewMessage()
+	if err := SetDecimalAmount(iso, 4, "1.00", "XXX"); err == nil || !strings.Contains(err.Error(), ErrUnknownCurrency.Error()) {
+		t.Errorf("unknown currency: err = %v", err)
+	}
+	if err := SetDecimalAmount(iso, 4, "1.5", "JPY"); err == nil {
+		t.Error("JPY with decimals accepted")
+	}
+	if err := SetDecimalAmount(iso, 7, "1.00", "USD"); err == nil {
+		t.Error("DE 7 accepted as an amount field")
+	}
+
+	iso.SetField(4, "000000000100")
+	if _, _, err := GetDecimalAmount(iso, 4); err == nil {
+		t.Error("DE 4 without DE 49 accepted")
+	}
+}
@@ -0,0 +1,33 @@
+package iso8583
+
+import _ "embed"
+
+var (
+	//go:embed spec/iso8583_1987.yml
+	defaultSpec []byte
+	//go:embed spec/iso8583_1993.yml
+	spec1993 []byte
+)
+
+// NewDefaultPackager returns a packager for the standard ISO 8583:1987 field
+// table shipped with the library, so no external spec file is needed.
+func NewDefaultPackager() *Packager {
+	return mustLoadEmbedded(defaultSpec)
+}
+
+// NewDefaultPackager1993 returns a packager for the ISO 8583:1993 field table
+// shipped with the library. It differs from the 1987 table in, among others,
+// the 12 digit local date and time (DE 12), the function, reason and
+// business codes (DE 24-26), the 3 digit action code (DE 39) and the
+// variable length DE 43, 53 and 56. Its Version only accepts 1xxx MTIs.
+func NewDefaultPackager1993() *Packager {
+	return mustLoadEmbedded(spec1993)
+}
+
+func mustLoadEmbedded(spec []byte) *Packager {
+	packager, err := LoadSpecFromBytes(spec)
+	if err != nil {
+		panic("iso8583: embedded spec is invalid: " + err.Error())
+	}
+	return packager
+}
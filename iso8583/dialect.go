@@ -0,0 +1,53 @@
+package iso8583
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed spec/dialects/*.yml
+var dialectSpecs embed.FS
+
+// Names of the specs built into the library, for NewPackager.
+const (
+	DialectISO1987    = "iso8583-1987"
+	DialectISO1993    = "iso8583-1993"
+	DialectVisaBASE1  = "visa-base1"
+	DialectMastercard = "mastercard"
+	DialectNPCI       = "npci"
+	DialectATMNDC     = "atm-ndc"
+)
+
+// NewPackager returns a packager for one of the specs built into the
+// library, see Dialects. The dialect specs cover the field lengths,
+// encodings and subfield layouts commonly agreed on; a network's own
+// specification still wins, so copy and adjust the spec when it differs:
+//
+//	packager, err := iso8583.NewPackager(iso8583.DialectVisaBASE1)
+func NewPackager(name string) (*Packager, error) {
+	switch name {
+	case DialectISO1987:
+		return NewDefaultPackager(), nil
+	case DialectISO1993:
+		return NewDefaultPackager1993(), nil
+	}
+
+	data, err := dialectSpecs.ReadFile("spec/dialects/" + name + ".yml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown dialect %q", name)
+	}
+	return LoadSpecFromBytes(data)
+}
+
+// Dialects lists the names accepted by NewPackager.
+func Dialects() []string {
+	names := []string{DialectISO1987, DialectISO1993}
+	entries, _ := dialectSpecs.ReadDir("spec/dialects")
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yml"))
+	}
+	sort.Strings(names)
+	return names
+}
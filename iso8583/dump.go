@@ -0,0 +1,124 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// dumpSpan is a run of wire bytes belonging to one field.
+type dumpSpan struct {
+	start, end int
+	label      string
+	ebcdic     bool
+}
+
+// DumpHex returns an offset/hex/ASCII view of message annotated with the
+// field each byte belongs to, parsed with the spec loaded by Load. Without a
+// loaded spec it is a plain hex dump.
+func DumpHex(message []byte) string {
+	pk := defaultPackager.Load()
+	if pk == nil {
+		return hex.Dump(message)
+	}
+	return pk.DumpHex(message)
+}
+
+// DumpHex returns an offset/hex/ASCII view of message with every field
+// starting on a new line, labelled with its number and Label:
+//
+//	00000000  30 32 30 30                                       |0200            |  [000] Message Type Indicator
+//	00000004  72 20 00 00 00 00 00 00                           |r ......        |  [001] Bitmap
+//	0000000c  31 36 34 31 31 31 31 31  31 31 31 31 31 31 31 31  |1641111111111111|  [002] Primary account number (PAN)
+//	0000001c  31 31                                             |11              |
+//
+// The message is parsed leniently, so a dump is produced for messages Parse
+// rejects: bytes after the point where parsing stopped are shown as
+// "unparsed" and the problems are listed at the end. EBCDIC fields are shown
+// translated in the text column.
+func (pk *Packager) DumpHex(message []byte) string {
+	pk = pk.active()
+	iso := pk.NewMessage().(*isoObject)
+	header := pk.Header.Length
+	var spans []dumpSpan
+	opts := ParseOptions{Mode: ParseLenient, trace: func(field, start, end int) {
+		fs, _ := pk.spec(field)
+		label := fmt.Sprintf("[%03d]", field)
+		if fs != nil && fs.Label != "" {
+			label += " " + fs.Label
+		}
+		spans = append(spans, dumpSpan{start: header + start, end: header + end, label: label, ebcdic: fs != nil && fs.ebcdic})
+	}}
+	err := iso.parse(message, opts)
+	if err == nil && header > 0 {
+		spans = append([]dumpSpan{{start: 0, end: header, label: "header"}}, spans...)
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for _, s := range spans {
+		if s.start > pos {
+			writeDumpSpan(&sb, message, dumpSpan{start: pos, end: s.start, label: "unparsed"})
+		}
+		writeDumpSpan(&sb, message, s)
+		pos = s.end
+	}
+	if err == nil && pk.Trailer.size() > 0 {
+		trailer := len(message) - pk.Trailer.size()
+		if trailer > pos {
+			writeDumpSpan(&sb, message, dumpSpan{start: pos, end: trailer, label: "unparsed"})
+		}
+		writeDumpSpan(&sb, message, dumpSpan{start: trailer, end: len(message), label: "trailer"})
+		pos = len(message)
+	}
+	if pos < len(message) {
+		writeDumpSpan(&sb, message, dumpSpan{start: pos, end: len(message), label: "unparsed"})
+	}
+
+	if err != nil {
+		fmt.Fprintf(&sb, "error: %v\n", err)
+	}
+	for _, w := range iso.warnings {
+		fmt.Fprintf(&sb, "warning: %v\n", w)
+	}
+	return sb.String()
+}
+
+// writeDumpSpan writes the bytes of s, 16 per line, labelling the first line.
+func writeDumpSpan(sb *strings.Builder, message []byte, s dumpSpan) {
+	label := s.label
+	for line := s.start; line < s.end; line += 16 {
+		chunk := message[line:min(line+16, s.end)]
+		fmt.Fprintf(sb, "%08x  ", line)
+		for i := 0; i < 16; i++ {
+			if i < len(chunk) {
+				fmt.Fprintf(sb, "%02x ", chunk[i])
+			} else {
+				sb.WriteString("   ")
+			}
+			if i == 7 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(" |")
+		for i := 0; i < 16; i++ {
+			c := byte(' ')
+			if i < len(chunk) {
+				c = chunk[i]
+				if s.ebcdic {
+					c = ebcdicToASCII[c]
+				}
+				if c < 0x20 || c > 0x7e {
+					c = '.'
+				}
+			}
+			sb.WriteByte(c)
+		}
+		sb.WriteString("|")
+		if label != "" {
+			sb.WriteString("  " + label)
+			label = ""
+		}
+		sb.WriteByte('\n')
+	}
+}
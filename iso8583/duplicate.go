@@ -0,0 +1,203 @@
+package iso8583
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDuplicateFields identify a request for duplicate checking: STAN,
+// transmission date and time and terminal ID.
+var DefaultDuplicateFields = []int{11, 7, 41}
+
+// DuplicateCache remembers the requests seen within the duplicate window and
+// the first response sent for each, e.g. in memory (MemoryDuplicateCache) or
+// in a store shared by several engine instances. Implementations must be
+// safe for concurrent use.
+type DuplicateCache interface {
+	// Add records key for ttl and reports whether it was new. A key already
+	// present and not yet expired is a duplicate.
+	Add(key string, ttl time.Duration) bool
+	// SetResponse stores the response sent for key.
+	SetResponse(key string, response []byte)
+	// Response returns the response stored for key, if any.
+	Response(key string) ([]byte, bool)
+	// Remove forgets key, so the next request with it is handled as new.
+	// The engine calls it when a request got no response.
+	Remove(key string)
+}
+
+// DuplicateCheck rejects requests seen before within Window, as financial
+// networks expect a retransmitted request to be answered without being
+// processed twice.
+type DuplicateCheck struct {
+	// Fields make up the request key together with the MTI, defaulting to
+	// DefaultDuplicateFields. A request missing any of them is not checked,
+	// since it cannot be told apart from others. Ignored when KeyFunc is
+	// set.
+	Fields []int
+	// KeyFunc, when set, builds the key of a request instead of Fields.
+	// Returning "" skips the check for that request.
+	KeyFunc func(iso ISO8583Object) string
+	// Window is how long a request is remembered. Defaults to 5m.
+	Window time.Duration
+	// Cache defaults to a MemoryDuplicateCache.
+	Cache DuplicateCache
+	// Handler is called for duplicates instead of the routed handler. When
+	// nil the engine replies with the response sent for the original
+	// request, or drops the duplicate while the original is still being
+	// handled.
+	Handler TcpHandler
+
+	once sync.Once
+}
+
+// DefaultDuplicateWindow is the DuplicateCheck Window when none is set.
+const DefaultDuplicateWindow = 5 * time.Minute
+
+func (d *DuplicateCheck) cache() DuplicateCache {
+	d.once.Do(func() {
+		if d.Cache == nil {
+			d.Cache = NewMemoryDuplicateCache()
+		}
+	})
+	return d.Cache
+}
+
+func (d *DuplicateCheck) window() time.Duration {
+	if d.Window > 0 {
+		return d.Window
+	}
+	return DefaultDuplicateWindow
+}
+
+// key returns the duplicate key of iso, "" when a key field is missing.
+// Repeats (e.g. 0201, 0421) share the key of the original so they are
+// answered with its response.
+func (d *DuplicateCheck) key(iso ISO8583Object) string {
+	if d.KeyFunc != nil {
+		return d.KeyFunc(iso)
+	}
+	fields := d.Fields
+	if len(fields) == 0 {
+		fields = DefaultDuplicateFields
+	}
+	mti := iso.GetMTI()
+	if len(mti) == 4 && mti[3] >= '1' && mti[3] <= '5' && (mti[3]-'0')%2 == 1 {
+		mti = mti[:3] + string(mti[3]-1)
+	}
+	parts := make([]string, 0, len(fields)+1)
+	parts = append(parts, mti)
+	for _, field := range fields {
+		value := iso.GetField(field)
+		if value == "" {
+			// Tanpa field kunci semua request jadi satu key
+			return ""
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, "|")
+}
+
+// checkDuplicate reports whether iso is a duplicate and answers it when so.
+// For new requests it returns the key to store the response under.
+func (t *TCPIso8583Engine) checkDuplicate(ctx context.Context, w *responseWriter, iso ISO8583Object) (string, bool) {
+	d := t.Duplicates
+	if !isRequestMTI(iso.GetMTI()) {
+		return "", false
+	}
+	key := d.key(iso)
+	if key == "" {
+		return "", false
+	}
+	cache := d.cache()
+	if cache.Add(key, d.window()) {
+		return key, false
+	}
+
+	w.log.Warn("duplicate request")
+	if d.Handler != nil {
+		t.wrap(d.Handler)(ctx, w, iso)
+		if !w.handled() {
+			t.writeDefaultResponse(ctx, w, iso)
+		}
+		return "", true
+	}
+	if resp, ok := cache.Response(key); ok {
+		if err := w.WriteRaw(resp); err != nil {
+			w.log.Error("write failed", "err", err)
+		}
+		return "", true
+	}
+	// Request asli masih diproses, responnya belum ada
+	w.Discard()
+	return "", true
+}
+
+// MemoryDuplicateCache is a DuplicateCache in process memory. Expired keys
+// are purged while adding new ones.
+type MemoryDuplicateCache struct {
+	mu        sync.Mutex
+	entries   map[string]*duplicateEntry
+	lastPurge time.Time
+}
+
+type duplicateEntry struct {
+	expires  time.Time
+	response []byte
+}
+
+// NewMemoryDuplicateCache creates an empty MemoryDuplicateCache.
+func NewMemoryDuplicateCache() *MemoryDuplicateCache {
+	return &MemoryDuplicateCache{entries: make(map[string]*duplicateEntry)}
+}
+
+// Add implements DuplicateCache.
+func (m *MemoryDuplicateCache) Add(key string, ttl time.Duration) bool {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Bersihkan yang kadaluarsa paling sering sekali per detik
+	if now.Sub(m.lastPurge) >= time.Second {
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastPurge = now
+	}
+	if e, ok := m.entries[key]; ok && !now.After(e.expires) {
+		return false
+	}
+	m.entries[key] = &duplicateEntry{expires: now.Add(ttl)}
+	return true
+}
+
+// SetResponse implements DuplicateCache. Only the first response of a key
+// is kept.
+func (m *MemoryDuplicateCache) SetResponse(key string, response []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && e.response == nil {
+		e.response = append([]byte(nil), response...)
+	}
+}
+
+// Response implements DuplicateCache.
+func (m *MemoryDuplicateCache) Response(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || e.response == nil || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.response, true
+}
+
+// Remove implements DuplicateCache.
+func (m *MemoryDuplicateCache) Remove(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
@@ -0,0 +1,45 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errMessageTruncated = errors.New("message truncated")
+
+// ParseError describes where parsing a message failed.
+type ParseError struct {
+	// Field is the data element being parsed (0 for MTI, 1 for bitmap, -1
+	// for unexpected data after the last field).
+	Field int
+	// Offset is the byte offset in the message where the field starts.
+	Offset int
+	// Expected is the number of bytes the field needs, 0 when unknown.
+	Expected int
+	// Remaining holds the unparsed bytes starting at Offset.
+	Remaining []byte
+	Err       error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("field %d at offset %d (expected %d bytes, %d remaining): %v",
+		e.Field, e.Offset, e.Expected, len(e.Remaining), e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(field int, message []byte, offset, expected int, err error) *ParseError {
+	var remaining []byte
+	if offset < len(message) {
+		remaining = message[offset:]
+	}
+	return &ParseError{
+		Field:     field,
+		Offset:    offset,
+		Expected:  expected,
+		Remaining: remaining,
+		Err:       err,
+	}
+}
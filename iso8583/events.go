@@ -0,0 +1,64 @@
+package iso8583
+
+import "time"
+
+// Event describes a message crossing the connection of an engine or client.
+type Event struct {
+	Time      time.Time
+	Direction Direction
+	// ConnID is the ConnInfo.ID of the engine connection, zero for a
+	// client.
+	ConnID uint64
+	// Peer is the remote address of the engine client, or the address of
+	// the host for a client.
+	Peer string
+	// Raw is the message as sent or received, without length header.
+	Raw []byte
+	// Message is the parsed form of Raw. It is nil when Raw does not
+	// parse.
+	Message ISO8583Object
+	// Latency is set on responses: for an engine the time since the
+	// request was read, for a client the round trip time. A client timeout
+	// error carries the time waited.
+	Latency time.Duration
+}
+
+// EventHook receives every message of an engine or client, e.g. to stream
+// the traffic to a fraud or analytics pipeline; see the publish package for
+// Kafka and NATS adapters.
+//
+// The methods are called on the connection goroutines and must not block:
+// hand the event to a queue and return. Event.Raw and Event.Message must not
+// be modified.
+type EventHook interface {
+	// OnInbound receives each message read and parsed.
+	OnInbound(ev Event)
+	// OnOutbound receives each message written.
+	OnOutbound(ev Event)
+	// OnError receives a message that failed to parse, compose, write or,
+	// for a client, get a response in time. Event.Message and Event.Raw
+	// hold what is known of it.
+	OnError(ev Event, err error)
+}
+
+// emitInbound and the like stamp ev and call hook, tolerating a nil hook.
+func emitInbound(hook EventHook, ev Event) {
+	if hook != nil {
+		ev.Time, ev.Direction = time.Now(), Inbound
+		hook.OnInbound(ev)
+	}
+}
+
+func emitOutbound(hook EventHook, ev Event) {
+	if hook != nil {
+		ev.Time, ev.Direction = time.Now(), Outbound
+		hook.OnOutbound(ev)
+	}
+}
+
+func emitError(hook EventHook, ev Event, err error) {
+	if hook != nil {
+		ev.Time = time.Now()
+		hook.OnError(ev, err)
+	}
+}
@@ -0,0 +1,87 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// memoryAddr is the address of the in-memory connection used by Exchange.
+type memoryAddr struct{}
+
+func (memoryAddr) Network() string { return "memory" }
+func (memoryAddr) String() string  { return "memory" }
+
+// Exchange runs message through the engine the way a request arriving on a
+// connection is handled, MAC check, network management, duplicate check,
+// middleware, routing, handler and response composing included, without
+// opening a socket. It returns the messages the engine sends back, none
+// when the request is dropped (the engine logs why). message has no length
+// header. With LengthHeaderNone several responses come back as one.
+//
+// It is meant for unit testing handlers:
+//
+//	engine := iso8583.GetEngine(30, 0) // routed by MTI
+//	engine.AddHandler(purchase, "0200")
+//	responses, err := engine.Exchange(request)
+func (t *TCPIso8583Engine) Exchange(message []byte) ([][]byte, error) {
+	return t.exchange(message, nil)
+}
+
+// ExchangePort is Exchange for a request arriving on port, parsed with its
+// Packager and routed with its Router.
+func (t *TCPIso8583Engine) ExchangePort(port PortConfig, message []byte) ([][]byte, error) {
+	return t.exchange(message, &port)
+}
+
+// ExchangeMessage composes iso, runs it through Exchange and parses the
+// responses with the spec of iso.
+func (t *TCPIso8583Engine) ExchangeMessage(iso ISO8583Object) ([]ISO8583Object, error) {
+	message, err := iso.ComposeBytes()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := t.Exchange(message)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]ISO8583Object, 0, len(raw))
+	for _, r := range raw {
+		resp := iso.Clone()
+		resp.Clear()
+		if err := resp.ParseBytes(r); err != nil {
+			return responses, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+func (t *TCPIso8583Engine) exchange(message []byte, port *PortConfig) ([][]byte, error) {
+	info := &ConnInfo{
+		ID:          t.connSeq.Add(1),
+		RemoteAddr:  memoryAddr{},
+		LocalAddr:   memoryAddr{},
+		ConnectedAt: time.Now(),
+		port:        port,
+	}
+	var buf bytes.Buffer
+	t.handleMessage(&buf, &sync.Mutex{}, info, message)
+
+	// Respon dibaca ulang dengan framing engine supaya terpisah per message
+	var responses [][]byte
+	reader := t.newReader(&buf)
+	for {
+		resp, err := reader.ReadMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return responses, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
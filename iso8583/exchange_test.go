@@ -0,0 +1,65 @@
+package iso8583
+
+import (
+	"context"
+	"testing"
+)
+
+func exchangeEngine(t *testing.T) *TCPIso8583Engine {
+	t.Helper()
+	if err := LoadFromBytes(defaultSpec); err != nil {
+		t.Fatal(err)
+	}
+	engine := GetEngine(30, 0)
+	engine.AddHandler(func(ctx context.Context, w ResponseWriter, req ISO8583Object) {
+		resp := NewResponseFrom(req)
+		resp.SetField(39, "00")
+		_ = w.Write(resp)
+	}, "0200")
+	return engine
+}
+
+func exchangeRequest(mti string) ISO8583Object {
+	iso := NewDefaultPackager().NewMessage()
+	iso.SetMTI(mti)
+	iso.SetField(3, "000000")
+	iso.SetField(4, "000000015000")
+	iso.SetField(7, "1017101010")
+	iso.SetField(11, "123456")
+	iso.SetField(41, "TERM0001")
+	return iso
+}
+
+func TestExchange(t *testing.T) {
+	engine := exchangeEngine(t)
+	request, err := exchangeRequest("0200").ComposeBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responses, err := engine.Exchange(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 1 {
+		t.Fatalf("got %d responses, want 1", len(responses))
+	}
+	resp := NewDefaultPackager().NewMessage()
+	if err := resp.ParseBytes(responses[0]); err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetMTI() != "0210" || resp.GetField(39) != "00" || resp.GetField(11) != "123456" {
+		t.Fatalf("unexpected response:\n%s", resp.PrettyPrint())
+	}
+}
+
+func TestExchangeNoRoute(t *testing.T) {
+	engine := exchangeEngine(t)
+	responses, err := engine.ExchangeMessage(exchangeRequest("0100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 0 {
+		t.Fatalf("got %d responses for an unrouted request, want none", len(responses))
+	}
+}
@@ -0,0 +1,205 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ChecksumLRC appends a longitudinal redundancy check (XOR of every byte)
+// after the trailer.
+const ChecksumLRC = "lrc"
+
+// ErrChecksum is returned by Parse when the trailer checksum does not match.
+var ErrChecksum = errors.New("trailer checksum mismatch")
+
+// FrameConfig describes a fixed-length header before the MTI or trailer
+// after the last field, e.g. a TPDU or institution header and an ETX:
+//
+//	Header:
+//	  Length: 5
+//	  Encoding: hex
+//	  Default: "6000010000"
+//	Trailer:
+//	  Length: 1
+//	  Encoding: hex
+//	  Default: "03"
+//	  Checksum: lrc
+//
+// Parse strips the header and trailer and keeps their values, see
+// GetHeader; ComposeMessage emits the message's own values or Default.
+type FrameConfig struct {
+	// Length is the size in bytes on the wire, checksum excluded.
+	Length int `yaml:"Length" json:"Length"`
+	// Encoding is EncodingHex to keep the value as hex text, for binary
+	// headers such as a TPDU. Otherwise the value is the bytes as is.
+	Encoding string `yaml:"Encoding" json:"Encoding"`
+	// Default is emitted when the message has no value of its own.
+	Default string `yaml:"Default" json:"Default"`
+	// Checksum is ChecksumLRC to follow the trailer with an LRC byte
+	// computed over the message body and the trailer. Trailer only.
+	Checksum string `yaml:"Checksum" json:"Checksum"`
+}
+
+// size returns the wire size including the checksum.
+func (f FrameConfig) size() int {
+	if f.Checksum == ChecksumLRC {
+		return f.Length + 1
+	}
+	return f.Length
+}
+
+// decode converts wire bytes to the stored value.
+func (f FrameConfig) decode(b []byte) string {
+	if f.Encoding == EncodingHex {
+		return strings.ToUpper(hex.EncodeToString(b))
+	}
+	return string(b)
+}
+
+// encode converts value, or Default when empty, to exactly Length wire bytes.
+func (f FrameConfig) encode(value string) ([]byte, error) {
+	if value == "" {
+		value = f.Default
+	}
+	b := []byte(value)
+	if f.Encoding == EncodingHex {
+		var err error
+		if b, err = hex.DecodeString(value); err != nil {
+			return nil, err
+		}
+	}
+	if len(b) != f.Length {
+		return nil, fmt.Errorf("length %d does not match Length %d", len(b), f.Length)
+	}
+	return b, nil
+}
+
+func (f FrameConfig) check(trailer bool) []error {
+	var problems []error
+	if f.Length < 0 {
+		problems = append(problems, fmt.Errorf("Length must not be negative, got %d", f.Length))
+	}
+	switch f.Encoding {
+	case "", EncodingASCII, EncodingHex:
+	default:
+		problems = append(problems, fmt.Errorf("unknown Encoding %q", f.Encoding))
+	}
+	if f.Default != "" {
+		if _, err := f.encode(f.Default); err != nil {
+			problems = append(problems, fmt.Errorf("Default: %w", err))
+		}
+	}
+	switch {
+	case f.Checksum == "":
+	case f.Checksum == ChecksumLRC && trailer:
+	default:
+		problems = append(problems, fmt.Errorf("unsupported Checksum %q", f.Checksum))
+	}
+	return problems
+}
+
+// unframe strips the header and trailer of message, storing their values,
+// and returns the body from the MTI on.
+func (p *isoObject) unframe(message []byte) ([]byte, error) {
+	header, trailer := p.packager.Header, p.packager.Trailer
+	if header.size()+trailer.size() == 0 {
+		return message, nil
+	}
+	if len(message) < header.size()+trailer.size() {
+		return nil, newParseError(-1, message, 0, header.size()+trailer.size(), errMessageTruncated)
+	}
+
+	p.header = header.decode(message[:header.Length])
+	end := len(message)
+	if trailer.Checksum == ChecksumLRC {
+		end--
+		if lrc(message[header.Length:end]) != message[end] {
+			return nil, newParseError(-1, message, end, 1, ErrChecksum)
+		}
+	}
+	p.trailer = trailer.decode(message[end-trailer.Length : end])
+	return message[header.Length : end-trailer.Length], nil
+}
+
+// appendFramed appends the whole message, header and trailer included, to
+// dst.
+func (p *isoObject) appendFramed(dst []byte) ([]byte, error) {
+	header, trailer := p.packager.Header, p.packager.Trailer
+	if header.size()+trailer.size() == 0 {
+		return p.appendBody(dst)
+	}
+
+	h, err := header.encode(p.header)
+	if err != nil {
+		return dst, fmt.Errorf("header: %w", err)
+	}
+	dst = append(dst, h...)
+	start := len(dst)
+	if dst, err = p.appendBody(dst); err != nil {
+		return dst, err
+	}
+	t, err := trailer.encode(p.trailer)
+	if err != nil {
+		return dst, fmt.Errorf("trailer: %w", err)
+	}
+	dst = append(dst, t...)
+	if trailer.Checksum == ChecksumLRC {
+		dst = append(dst, lrc(dst[start:]))
+	}
+	return dst, nil
+}
+
+// body returns the part of raw, a wire message of iso, from the MTI to the
+// last field.
+func body(iso ISO8583Object, raw []byte) []byte {
+	p, ok := iso.(*isoObject)
+	if !ok {
+		return raw
+	}
+	header, trailer := p.packager.Header.size(), p.packager.Trailer.size()
+	if len(raw) < header+trailer {
+		return raw
+	}
+	return raw[header : len(raw)-trailer]
+}
+
+func lrc(b []byte) byte {
+	var sum byte
+	for _, c := range b {
+		sum ^= c
+	}
+	return sum
+}
+
+// GetHeader implements ISO8583Object. It returns the header parsed with the
+// message or set with SetHeader, hex encoded for a hex header.
+func (p *isoObject) GetHeader() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.header
+}
+
+// SetHeader implements ISO8583Object. An empty header composes as the spec
+// Default.
+func (p *isoObject) SetHeader(val string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.header = val
+}
+
+// GetTrailer implements ISO8583Object.
+func (p *isoObject) GetTrailer() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.trailer
+}
+
+// SetTrailer implements ISO8583Object. An empty trailer composes as the spec
+// Default.
+func (p *isoObject) SetTrailer(val string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.trailer = val
+}
@@ -0,0 +1,214 @@
+package iso8583
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LengthHeader selects how the message length is framed on the wire.
+type LengthHeader int
+
+const (
+	// LengthHeaderASCII4 prefixes 4 ASCII digits, e.g. "0123" (default).
+	LengthHeaderASCII4 LengthHeader = iota
+	// LengthHeaderASCII2 prefixes 2 ASCII digits.
+	LengthHeaderASCII2
+	// LengthHeaderBinary2 prefixes a 2-byte big-endian length.
+	LengthHeaderBinary2
+	// LengthHeaderBinary4 prefixes a 4-byte big-endian length.
+	LengthHeaderBinary4
+	// LengthHeaderBCD prefixes 4 digits packed in 2 BCD bytes.
+	LengthHeaderBCD
+	// LengthHeaderNone sends the message as is; a read returns whatever a
+	// single read on the connection delivers.
+	LengthHeaderNone
+)
+
+// DefaultMaxFrameSize is the largest message a reader accepts when no
+// MaxFrameSize is set. It keeps a peer from making the reader allocate
+// whatever a binary length header announces.
+const DefaultMaxFrameSize = 64 * 1024
+
+// ErrFrameTooLarge is returned when a length header announces a message
+// larger than the reader accepts.
+var ErrFrameTooLarge = errors.New("iso8583 frame too large")
+
+// frameLimit returns maxSize, or DefaultMaxFrameSize when it is not set.
+func frameLimit(maxSize int) int {
+	if maxSize > 0 {
+		return maxSize
+	}
+	return DefaultMaxFrameSize
+}
+
+func (h LengthHeader) size() int {
+	switch h {
+	case LengthHeaderASCII2, LengthHeaderBinary2, LengthHeaderBCD:
+		return 2
+	case LengthHeaderNone:
+		return 0
+	default:
+		return 4
+	}
+}
+
+func (h LengthHeader) maxLength() int {
+	switch h {
+	case LengthHeaderASCII2:
+		return 99
+	case LengthHeaderBinary2:
+		return 0xFFFF
+	case LengthHeaderBinary4:
+		return 0x7FFFFFFF
+	default:
+		return 9999
+	}
+}
+
+// readFrame reads one message framed with h, refusing messages over
+// maxSize bytes (DefaultMaxFrameSize when zero).
+func (h LengthHeader) readFrame(r io.Reader, maxSize int) ([]byte, error) {
+	if h == LengthHeaderNone {
+		buf := make([]byte, frameLimit(maxSize))
+		n, err := r.Read(buf)
+		if n > 0 {
+			return buf[:n], nil
+		}
+		return nil, err
+	}
+
+	header := make([]byte, h.size())
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length, err := h.decode(header, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeFrame writes message framed with h.
+func (h LengthHeader) writeFrame(w io.Writer, message []byte) error {
+	frame, err := h.appendHeader(make([]byte, 0, h.size()+len(message)), len(message))
+	if err != nil {
+		return err
+	}
+	frame = append(frame, message...)
+	_, err = w.Write(frame)
+	return err
+}
+
+// decode returns the length in header, checked against maxSize before the
+// caller allocates the body.
+func (h LengthHeader) decode(header []byte, maxSize int) (int, error) {
+	length, err := h.decodeLength(header)
+	if err != nil {
+		return 0, err
+	}
+	if limit := frameLimit(maxSize); length > limit {
+		return 0, fmt.Errorf("%w: length header %d exceeds %d", ErrFrameTooLarge, length, limit)
+	}
+	return length, nil
+}
+
+func (h LengthHeader) decodeLength(header []byte) (int, error) {
+	switch h {
+	case LengthHeaderBinary2:
+		return int(binary.BigEndian.Uint16(header)), nil
+	case LengthHeaderBinary4:
+		length := binary.BigEndian.Uint32(header)
+		if length > uint32(h.maxLength()) {
+			return 0, fmt.Errorf("invalid length header %d", length)
+		}
+		return int(length), nil
+	case LengthHeaderBCD:
+		length := 0
+		for _, b := range header {
+			hi, lo := int(b>>4), int(b&0x0F)
+			if hi > 9 || lo > 9 {
+				return 0, fmt.Errorf("invalid BCD length header % X", header)
+			}
+			length = length*100 + hi*10 + lo
+		}
+		return length, nil
+	default:
+		return parseLength(string(header))
+	}
+}
+
+func (h LengthHeader) appendHeader(dst []byte, length int) ([]byte, error) {
+	if length > h.maxLength() {
+		return nil, fmt.Errorf("message length %d exceeds length header capacity", length)
+	}
+
+	switch h {
+	case LengthHeaderNone:
+		return dst, nil
+	case LengthHeaderASCII2:
+		return fmt.Appendf(dst, "%02d", length), nil
+	case LengthHeaderBinary2:
+		return binary.BigEndian.AppendUint16(dst, uint16(length)), nil
+	case LengthHeaderBinary4:
+		return binary.BigEndian.AppendUint32(dst, uint32(length)), nil
+	case LengthHeaderBCD:
+		return append(dst, byte(length/1000)<<4|byte(length/100%10), byte(length/10%10)<<4|byte(length%10)), nil
+	default:
+		return fmt.Appendf(dst, "%04d", length), nil
+	}
+}
+
+// MessageReader reads length-framed messages from a stream, e.g. a
+// net.Conn, one complete message at a time. Messages split over several TCP
+// segments or coalesced into one are reassembled through an internal
+// buffer, so a MessageReader must be the only reader of the stream.
+type MessageReader struct {
+	// MaxFrameSize is the largest message accepted; a larger length header
+	// fails ReadMessage with ErrFrameTooLarge. Defaults to
+	// DefaultMaxFrameSize.
+	MaxFrameSize int
+
+	header LengthHeader
+	r      *bufio.Reader
+}
+
+// newReader returns a MessageReader with the engine framing and limit.
+func (t *TCPIso8583Engine) newReader(r io.Reader) *MessageReader {
+	reader := NewMessageReader(r, t.LengthHeader)
+	reader.MaxFrameSize = t.MaxFrameSize
+	return reader
+}
+
+// NewMessageReader creates a MessageReader for messages framed with header.
+func NewMessageReader(r io.Reader, header LengthHeader) *MessageReader {
+	return &MessageReader{header: header, r: bufio.NewReader(r)}
+}
+
+// ReadMessage returns the next message without its length header. It
+// returns io.EOF when the stream ends between messages and
+// io.ErrUnexpectedEOF when it ends inside one.
+func (m *MessageReader) ReadMessage() ([]byte, error) {
+	return m.header.readFrame(m.r, m.MaxFrameSize)
+}
+
+// Wait blocks until the first byte of the next message is available,
+// without consuming it. Callers use it to tell an idle stream from a slow
+// message, e.g. to apply a shorter read deadline once a message starts.
+func (m *MessageReader) Wait() error {
+	_, err := m.r.Peek(1)
+	return err
+}
+
+// WriteMessage writes message to w framed with header in a single Write.
+func WriteMessage(w io.Writer, header LengthHeader, message []byte) error {
+	return header.writeFrame(w, message)
+}
@@ -0,0 +1,317 @@
+// ISO 8583 bridge service: lets services that do not speak raw ISO 8583
+// send requests over the link and watch the messages it carries.
+//
+// Regenerate the Go code from the repository root with
+//
+//   protoc --go_out=. --go_opt=module=github.com/randyardiansyah25/go-iso8583 \
+//     --go-grpc_out=. --go-grpc_opt=module=github.com/randyardiansyah25/go-iso8583 \
+//     iso8583/grpcbridge/bridgepb/bridge.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: iso8583/grpcbridge/bridgepb/bridge.proto
+
+package bridgepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Message is an ISO 8583 message in its parsed form.
+type Message struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Mti   string                 `protobuf:"bytes,1,opt,name=mti,proto3" json:"mti,omitempty"`
+	// Fields holds the data elements keyed by field number, 2 and up.
+	Fields        map[int32]string `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Header        string           `protobuf:"bytes,3,opt,name=header,proto3" json:"header,omitempty"`
+	Trailer       string           `protobuf:"bytes,4,opt,name=trailer,proto3" json:"trailer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetMti() string {
+	if x != nil {
+		return x.Mti
+	}
+	return ""
+}
+
+func (x *Message) GetFields() map[int32]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Message) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *Message) GetTrailer() string {
+	if x != nil {
+		return x.Trailer
+	}
+	return ""
+}
+
+type SendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *Message               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendRequest) Reset() {
+	*x = SendRequest{}
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendRequest) ProtoMessage() {}
+
+func (x *SendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendRequest.ProtoReflect.Descriptor instead.
+func (*SendRequest) Descriptor() ([]byte, []int) {
+	return file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SendRequest) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type SendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *Message               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendResponse) Reset() {
+	*x = SendResponse{}
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendResponse) ProtoMessage() {}
+
+func (x *SendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendResponse.ProtoReflect.Descriptor instead.
+func (*SendResponse) Descriptor() ([]byte, []int) {
+	return file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SendResponse) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// MTI prefixes to receive, e.g. "08" or "0420". Empty receives every
+	// message.
+	MtiPrefixes   []string `protobuf:"bytes,1,rep,name=mti_prefixes,json=mtiPrefixes,proto3" json:"mti_prefixes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubscribeRequest) GetMtiPrefixes() []string {
+	if x != nil {
+		return x.MtiPrefixes
+	}
+	return nil
+}
+
+var File_iso8583_grpcbridge_bridgepb_bridge_proto protoreflect.FileDescriptor
+
+const file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDesc = "" +
+	"\n" +
+	"(iso8583/grpcbridge/bridgepb/bridge.proto\x12\x11iso8583.bridge.v1\"\xc8\x01\n" +
+	"\aMessage\x12\x10\n" +
+	"\x03mti\x18\x01 \x01(\tR\x03mti\x12>\n" +
+	"\x06fields\x18\x02 \x03(\v2&.iso8583.bridge.v1.Message.FieldsEntryR\x06fields\x12\x16\n" +
+	"\x06header\x18\x03 \x01(\tR\x06header\x12\x18\n" +
+	"\atrailer\x18\x04 \x01(\tR\atrailer\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
+	"\vSendRequest\x124\n" +
+	"\amessage\x18\x01 \x01(\v2\x1a.iso8583.bridge.v1.MessageR\amessage\"D\n" +
+	"\fSendResponse\x124\n" +
+	"\amessage\x18\x01 \x01(\v2\x1a.iso8583.bridge.v1.MessageR\amessage\"5\n" +
+	"\x10SubscribeRequest\x12!\n" +
+	"\fmti_prefixes\x18\x01 \x03(\tR\vmtiPrefixes2\xa4\x01\n" +
+	"\tISOBridge\x12G\n" +
+	"\x04Send\x12\x1e.iso8583.bridge.v1.SendRequest\x1a\x1f.iso8583.bridge.v1.SendResponse\x12N\n" +
+	"\tSubscribe\x12#.iso8583.bridge.v1.SubscribeRequest\x1a\x1a.iso8583.bridge.v1.Message0\x01BEZCgithub.com/randyardiansyah25/go-iso8583/iso8583/grpcbridge/bridgepbb\x06proto3"
+
+var (
+	file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescOnce sync.Once
+	file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescData []byte
+)
+
+func file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescGZIP() []byte {
+	file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescOnce.Do(func() {
+		file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDesc), len(file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDesc)))
+	})
+	return file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDescData
+}
+
+var file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_iso8583_grpcbridge_bridgepb_bridge_proto_goTypes = []any{
+	(*Message)(nil),          // 0: iso8583.bridge.v1.Message
+	(*SendRequest)(nil),      // 1: iso8583.bridge.v1.SendRequest
+	(*SendResponse)(nil),     // 2: iso8583.bridge.v1.SendResponse
+	(*SubscribeRequest)(nil), // 3: iso8583.bridge.v1.SubscribeRequest
+	nil,                      // 4: iso8583.bridge.v1.Message.FieldsEntry
+}
+var file_iso8583_grpcbridge_bridgepb_bridge_proto_depIdxs = []int32{
+	4, // 0: iso8583.bridge.v1.Message.fields:type_name -> iso8583.bridge.v1.Message.FieldsEntry
+	0, // 1: iso8583.bridge.v1.SendRequest.message:type_name -> iso8583.bridge.v1.Message
+	0, // 2: iso8583.bridge.v1.SendResponse.message:type_name -> iso8583.bridge.v1.Message
+	1, // 3: iso8583.bridge.v1.ISOBridge.Send:input_type -> iso8583.bridge.v1.SendRequest
+	3, // 4: iso8583.bridge.v1.ISOBridge.Subscribe:input_type -> iso8583.bridge.v1.SubscribeRequest
+	2, // 5: iso8583.bridge.v1.ISOBridge.Send:output_type -> iso8583.bridge.v1.SendResponse
+	0, // 6: iso8583.bridge.v1.ISOBridge.Subscribe:output_type -> iso8583.bridge.v1.Message
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_iso8583_grpcbridge_bridgepb_bridge_proto_init() }
+func file_iso8583_grpcbridge_bridgepb_bridge_proto_init() {
+	if File_iso8583_grpcbridge_bridgepb_bridge_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDesc), len(file_iso8583_grpcbridge_bridgepb_bridge_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_iso8583_grpcbridge_bridgepb_bridge_proto_goTypes,
+		DependencyIndexes: file_iso8583_grpcbridge_bridgepb_bridge_proto_depIdxs,
+		MessageInfos:      file_iso8583_grpcbridge_bridgepb_bridge_proto_msgTypes,
+	}.Build()
+	File_iso8583_grpcbridge_bridgepb_bridge_proto = out.File
+	file_iso8583_grpcbridge_bridgepb_bridge_proto_goTypes = nil
+	file_iso8583_grpcbridge_bridgepb_bridge_proto_depIdxs = nil
+}
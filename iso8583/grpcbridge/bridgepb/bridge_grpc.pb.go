@@ -0,0 +1,182 @@
+// ISO 8583 bridge service: lets services that do not speak raw ISO 8583
+// send requests over the link and watch the messages it carries.
+//
+// Regenerate the Go code from the repository root with
+//
+//   protoc --go_out=. --go_opt=module=github.com/randyardiansyah25/go-iso8583 \
+//     --go-grpc_out=. --go-grpc_opt=module=github.com/randyardiansyah25/go-iso8583 \
+//     iso8583/grpcbridge/bridgepb/bridge.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: iso8583/grpcbridge/bridgepb/bridge.proto
+
+package bridgepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ISOBridge_Send_FullMethodName      = "/iso8583.bridge.v1.ISOBridge/Send"
+	ISOBridge_Subscribe_FullMethodName = "/iso8583.bridge.v1.ISOBridge/Subscribe"
+)
+
+// ISOBridgeClient is the client API for ISOBridge service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ISOBridge forwards requests over an ISO 8583 link.
+type ISOBridgeClient interface {
+	// Send forwards a request and returns its response.
+	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
+	// Subscribe streams the messages published by the bridge, such as
+	// requests arriving at an engine or unsolicited responses of a client.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
+}
+
+type iSOBridgeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewISOBridgeClient(cc grpc.ClientConnInterface) ISOBridgeClient {
+	return &iSOBridgeClient{cc}
+}
+
+func (c *iSOBridgeClient) Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendResponse)
+	err := c.cc.Invoke(ctx, ISOBridge_Send_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iSOBridgeClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ISOBridge_ServiceDesc.Streams[0], ISOBridge_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Message]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ISOBridge_SubscribeClient = grpc.ServerStreamingClient[Message]
+
+// ISOBridgeServer is the server API for ISOBridge service.
+// All implementations must embed UnimplementedISOBridgeServer
+// for forward compatibility.
+//
+// ISOBridge forwards requests over an ISO 8583 link.
+type ISOBridgeServer interface {
+	// Send forwards a request and returns its response.
+	Send(context.Context, *SendRequest) (*SendResponse, error)
+	// Subscribe streams the messages published by the bridge, such as
+	// requests arriving at an engine or unsolicited responses of a client.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Message]) error
+	mustEmbedUnimplementedISOBridgeServer()
+}
+
+// UnimplementedISOBridgeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedISOBridgeServer struct{}
+
+func (UnimplementedISOBridgeServer) Send(context.Context, *SendRequest) (*SendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Send not implemented")
+}
+func (UnimplementedISOBridgeServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Message]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedISOBridgeServer) mustEmbedUnimplementedISOBridgeServer() {}
+func (UnimplementedISOBridgeServer) testEmbeddedByValue()                   {}
+
+// UnsafeISOBridgeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ISOBridgeServer will
+// result in compilation errors.
+type UnsafeISOBridgeServer interface {
+	mustEmbedUnimplementedISOBridgeServer()
+}
+
+func RegisterISOBridgeServer(s grpc.ServiceRegistrar, srv ISOBridgeServer) {
+	// If the following call pancis, it indicates UnimplementedISOBridgeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ISOBridge_ServiceDesc, srv)
+}
+
+func _ISOBridge_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ISOBridgeServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ISOBridge_Send_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ISOBridgeServer).Send(ctx, req.(*SendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ISOBridge_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ISOBridgeServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Message]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ISOBridge_SubscribeServer = grpc.ServerStreamingServer[Message]
+
+// ISOBridge_ServiceDesc is the grpc.ServiceDesc for ISOBridge service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ISOBridge_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "iso8583.bridge.v1.ISOBridge",
+	HandlerType: (*ISOBridgeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _ISOBridge_Send_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _ISOBridge_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "iso8583/grpcbridge/bridgepb/bridge.proto",
+}
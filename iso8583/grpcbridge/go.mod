@@ -0,0 +1,22 @@
+module github.com/randyardiansyah25/go-iso8583/iso8583/grpcbridge
+
+go 1.23.2
+
+toolchain go1.24.0
+
+require (
+	github.com/randyardiansyah25/go-iso8583 v0.0.0
+	google.golang.org/grpc v1.71.1
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Dikembangkan bersama modul utama di repo yang sama
+replace github.com/randyardiansyah25/go-iso8583 => ../..
@@ -0,0 +1,270 @@
+// Package grpcbridge serves the ISOBridge gRPC service of bridgepb, giving
+// services in any language access to an ISO 8583 link: Send forwards a
+// request through an ISO client and Subscribe streams the messages the
+// bridge publishes.
+//
+//	client := iso8583.NewClient("switch:7001", 30)
+//	...
+//	bridge := grpcbridge.New(client, packager)
+//	client.OnUnmatched = bridge.Publish
+//
+//	server := grpc.NewServer()
+//	bridgepb.RegisterISOBridgeServer(server, bridge)
+//	server.Serve(listener)
+//
+// Engines publish the requests they receive with Middleware:
+//
+//	engine.Use(bridge.Middleware())
+//
+// The package is a module of its own, so users of the core package do not
+// depend on gRPC:
+//
+//	go get github.com/randyardiansyah25/go-iso8583/iso8583/grpcbridge
+package grpcbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+	"github.com/randyardiansyah25/go-iso8583/iso8583/grpcbridge/bridgepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultSubscriberBuffer is the number of messages queued for a subscriber
+// when Bridge.SubscriberBuffer is not set.
+const DefaultSubscriberBuffer = 64
+
+// Sender forwards a request and returns its response, e.g. an
+// *iso8583.ISOClient or an *iso8583.Pool.
+type Sender interface {
+	Send(iso iso8583.ISO8583Object) (iso8583.ISO8583Object, error)
+}
+
+// Bridge implements bridgepb.ISOBridgeServer.
+type Bridge struct {
+	bridgepb.UnimplementedISOBridgeServer
+
+	Sender Sender
+	// Packager composes the requests. When nil the spec loaded by
+	// iso8583.Load is used.
+	Packager *iso8583.Packager
+	// STAN, when set, fills DE 11 of requests that lack it.
+	STAN *iso8583.STANGenerator
+	// Unmasked sends responses and published messages as they are. By
+	// default their sensitive fields (PAN, track data, PIN block) are
+	// redacted, see iso8583.ISO8583Object.Masked, since any subscriber
+	// sees every message.
+	Unmasked bool
+	// SubscriberBuffer is the number of messages queued for each
+	// subscriber. Messages for a subscriber whose queue is full are
+	// dropped. Defaults to DefaultSubscriberBuffer.
+	SubscriberBuffer int
+	// Logger receives forwarding errors and dropped messages. Defaults to
+	// slog.Default.
+	Logger *slog.Logger
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	prefixes []string
+	ch       chan *bridgepb.Message
+}
+
+// New creates a bridge sending through sender with the spec of packager.
+func New(sender Sender, packager *iso8583.Packager) *Bridge {
+	return &Bridge{Sender: sender, Packager: packager}
+}
+
+// Send forwards the request and returns its response. Failures map to
+// InvalidArgument for a message that does not compose, DeadlineExceeded
+// when the host does not answer in time and Unavailable when it cannot be
+// reached.
+func (b *Bridge) Send(ctx context.Context, in *bridgepb.SendRequest) (*bridgepb.SendResponse, error) {
+	if b.Sender == nil {
+		return nil, status.Error(codes.Unimplemented, "bridge has no sender")
+	}
+	if in.GetMessage() == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing message")
+	}
+	req, err := b.newMessage()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := Decode(in.GetMessage(), req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if b.STAN != nil && !req.HasField(11) {
+		stan, err := b.STAN.Next()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		req.SetField(11, stan)
+	}
+	// Compose dulu supaya kesalahan field jadi InvalidArgument, bukan kesalahan host
+	if _, err := req.ComposeBytes(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp, err := b.Sender.Send(req)
+	if err != nil {
+		b.log().Error("forward failed", "mti", req.GetMTI(), "stan", req.GetField(11), "err", err)
+		return nil, status.Error(sendCode(err), err.Error())
+	}
+	if !b.Unmasked {
+		resp = resp.Masked()
+	}
+	return &bridgepb.SendResponse{Message: Encode(resp)}, nil
+}
+
+// Subscribe streams the messages passed to Publish whose MTI starts with
+// one of the requested prefixes, until the client goes away.
+func (b *Bridge) Subscribe(in *bridgepb.SubscribeRequest, stream bridgepb.ISOBridge_SubscribeServer) error {
+	size := b.SubscriberBuffer
+	if size <= 0 {
+		size = DefaultSubscriberBuffer
+	}
+	sub := &subscriber{prefixes: in.GetMtiPrefixes(), ch: make(chan *bridgepb.Message, size)}
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[*subscriber]struct{})
+	}
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case message := <-sub.ch:
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Publish hands iso to the current subscribers. It does not block: a
+// subscriber that is not keeping up misses the message. Its signature fits
+// iso8583.ISOClient.OnUnmatched.
+func (b *Bridge) Publish(iso iso8583.ISO8583Object) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subscribers) == 0 {
+		return
+	}
+	if !b.Unmasked {
+		iso = iso.Masked()
+	}
+	message := Encode(iso)
+	for sub := range b.subscribers {
+		if !sub.wants(message.Mti) {
+			continue
+		}
+		select {
+		case sub.ch <- message:
+		default:
+			b.log().Warn("subscriber is full, message dropped", "mti", message.Mti, "stan", iso.GetField(11))
+		}
+	}
+}
+
+// Middleware returns an engine middleware publishing every request before
+// it is handled.
+func (b *Bridge) Middleware() iso8583.Middleware {
+	return func(next iso8583.TcpHandler) iso8583.TcpHandler {
+		return func(ctx context.Context, w iso8583.ResponseWriter, iso iso8583.ISO8583Object) {
+			b.Publish(iso)
+			next(ctx, w, iso)
+		}
+	}
+}
+
+func (s *subscriber) wants(mti string) bool {
+	if len(s.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(mti, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bridge) newMessage() (iso8583.ISO8583Object, error) {
+	if b.Packager != nil {
+		return b.Packager.NewMessage(), nil
+	}
+	return iso8583.NewISO8583()
+}
+
+// log returns Logger, or slog.Default when none is set.
+func (b *Bridge) log() *slog.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
+	return slog.Default()
+}
+
+// sendCode maps a Send error to a gRPC status code.
+func sendCode(err error) codes.Code {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, iso8583.ErrResponseTimeout):
+		return codes.DeadlineExceeded
+	case errors.Is(err, iso8583.ErrClientClosed), errors.Is(err, iso8583.ErrNoConnection), errors.As(err, &netErr):
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// Encode returns the protobuf form of iso.
+func Encode(iso iso8583.ISO8583Object) *bridgepb.Message {
+	message := &bridgepb.Message{
+		Mti:     iso.GetMTI(),
+		Header:  iso.GetHeader(),
+		Trailer: iso.GetTrailer(),
+		Fields:  make(map[int32]string),
+	}
+	for _, field := range iso.Fields() {
+		if field > 1 {
+			message.Fields[int32(field)] = iso.GetField(field)
+		}
+	}
+	return message
+}
+
+// Decode sets iso from its protobuf form, see Encode.
+func Decode(message *bridgepb.Message, iso iso8583.ISO8583Object) error {
+	if message.GetMti() == "" {
+		return errors.New("invalid message: no mti")
+	}
+	iso.SetMTI(message.GetMti())
+	if header := message.GetHeader(); header != "" {
+		iso.SetHeader(header)
+	}
+	if trailer := message.GetTrailer(); trailer != "" {
+		iso.SetTrailer(trailer)
+	}
+	for field, value := range message.GetFields() {
+		if field < 2 {
+			return fmt.Errorf("invalid message: unknown field %d", field)
+		}
+		iso.SetField(int(field), value)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// EngineHealth is the state of an engine, see TCPIso8583Engine.Health.
+type EngineHealth struct {
+	// Listeners are the addresses the engine accepts connections on.
+	Listeners         []string `json:"listeners"`
+	ActiveConnections int      `json:"active_connections"`
+	ShuttingDown      bool     `json:"shutting_down"`
+}
+
+// Healthy reports whether the engine is accepting connections.
+func (h EngineHealth) Healthy() bool {
+	return len(h.Listeners) > 0 && !h.ShuttingDown
+}
+
+// ClientHealth is the state of a client link, see ISOClient.Health.
+type ClientHealth struct {
+	Address string `json:"address"`
+	State   string `json:"state"`
+	// Pending is the number of requests waiting for their response.
+	Pending int `json:"pending"`
+	// LastEcho is when an 0800 echo (DE 70 NetworkEcho) was last answered,
+	// zero when none was.
+	LastEcho time.Time `json:"last_echo"`
+	// LastResponse is when any message was last received from the host.
+	LastResponse time.Time `json:"last_response"`
+	// SAFDepth is the number of messages waiting in the SAF queue. SAFError
+	// is set instead when the store could not be read.
+	SAFDepth int    `json:"saf_depth"`
+	SAFError string `json:"saf_error,omitempty"`
+}
+
+// Health returns the listener and connection state of the engine.
+func (t *TCPIso8583Engine) Health() EngineHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := EngineHealth{
+		Listeners:         make([]string, 0, len(t.listeners)),
+		ActiveConnections: len(t.activeConns),
+		ShuttingDown:      t.inShutdown.Load(),
+	}
+	for listener := range t.listeners {
+		h.Listeners = append(h.Listeners, listener.Addr().String())
+	}
+	return h
+}
+
+// Health returns the state of the link to the host.
+func (c *ISOClient) Health() ClientHealth {
+	c.mu.Lock()
+	state := ConnState(c.state.Load())
+	if state == StateConnected && (c.conn == nil || c.err != nil) {
+		// Close tidak melewati setState
+		state = StateDisconnected
+	}
+	h := ClientHealth{
+		Address: c.Address,
+		State:   state.String(),
+		Pending: len(c.pending),
+	}
+	c.mu.Unlock()
+	h.LastEcho = unixTime(c.lastEcho.Load())
+	h.LastResponse = unixTime(c.lastResponse.Load())
+	if c.SAF != nil && c.SAF.Store != nil {
+		entries, err := c.SAF.Store.Pending()
+		if err != nil {
+			h.SAFError = err.Error()
+		}
+		h.SAFDepth = len(entries)
+	}
+	return h
+}
+
+// Health returns the state of every pooled connection.
+func (p *Pool) Health() []ClientHealth {
+	health := make([]ClientHealth, len(p.clients))
+	for i, client := range p.clients {
+		health[i] = client.Health()
+	}
+	return health
+}
+
+func unixTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
+
+// isEcho reports whether iso is a network management echo.
+func isEcho(iso ISO8583Object) bool {
+	mti := iso.GetMTI()
+	return len(mti) == 4 && mti[1] == '8' && iso.GetField(70) == NetworkEcho
+}
+
+// HealthCheck is an http.Handler for Kubernetes probes reporting an engine
+// and the links to upstream hosts as JSON:
+//
+//	http.Handle("/healthz", &iso8583.HealthCheck{Engine: engine, Clients: []*iso8583.ISOClient{client}, MaxEchoAge: 2 * time.Minute})
+//
+// It answers 200 when healthy and 503 otherwise: the engine is not
+// listening, a client is not connected, a pool has no connection up, or an
+// echo is older than MaxEchoAge.
+type HealthCheck struct {
+	Engine  *TCPIso8583Engine
+	Clients []*ISOClient
+	Pools   []*Pool
+	// MaxEchoAge, when set, fails links whose last answered echo is older,
+	// or that never had one. Pair it with Pool.HealthCheckInterval or an
+	// application echo loop.
+	MaxEchoAge time.Duration
+}
+
+// HealthReport is the body served by HealthCheck.
+type HealthReport struct {
+	Healthy bool             `json:"healthy"`
+	Engine  *EngineHealth    `json:"engine,omitempty"`
+	Clients []ClientHealth   `json:"clients,omitempty"`
+	Pools   [][]ClientHealth `json:"pools,omitempty"`
+}
+
+// Report collects the health of everything checked.
+func (h *HealthCheck) Report() HealthReport {
+	report := HealthReport{Healthy: true}
+	if h.Engine != nil {
+		engine := h.Engine.Health()
+		report.Engine = &engine
+		report.Healthy = engine.Healthy()
+	}
+	for _, client := range h.Clients {
+		health := client.Health()
+		report.Clients = append(report.Clients, health)
+		if !h.linkHealthy(health) {
+			report.Healthy = false
+		}
+	}
+	for _, pool := range h.Pools {
+		health := pool.Health()
+		report.Pools = append(report.Pools, health)
+		// Pool masih bisa melayani selama satu koneksi sehat
+		up := false
+		for _, conn := range health {
+			up = up || h.linkHealthy(conn)
+		}
+		if !up {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func (h *HealthCheck) linkHealthy(health ClientHealth) bool {
+	if health.State != StateConnected.String() {
+		return false
+	}
+	return h.MaxEchoAge <= 0 || (!health.LastEcho.IsZero() && time.Since(health.LastEcho) <= h.MaxEchoAge)
+}
+
+// ServeHTTP writes Report as JSON, with status 503 when unhealthy.
+func (h *HealthCheck) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := h.Report()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
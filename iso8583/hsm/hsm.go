@@ -0,0 +1,77 @@
+// Package hsm abstracts the cryptographic operations a switch offloads to a
+// hardware security module: MAC generation, PIN verification and PIN block
+// translation.
+//
+// Keys are passed as KeyRef values whose meaning depends on the
+// implementation: a key name for Software, a key cryptogram under the LMK
+// for Thales.
+package hsm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583/pinblock"
+)
+
+// KeyRef identifies a key to the HSM.
+type KeyRef string
+
+// ErrVerificationFailed is returned when a PIN or MAC does not verify.
+var ErrVerificationFailed = errors.New("hsm: verification failed")
+
+// HSM is a key manager performing cryptographic operations with keys it
+// holds.
+type HSM interface {
+	// GenerateMAC returns the MAC of data under key.
+	GenerateMAC(ctx context.Context, key KeyRef, data []byte) ([]byte, error)
+	// VerifyPIN checks the PIN carried in an enciphered PIN block against
+	// its Visa PIN verification value. It returns ErrVerificationFailed for
+	// a wrong PIN.
+	VerifyPIN(ctx context.Context, req PINVerification) error
+	// TranslatePINBlock re-enciphers a PIN block from one zone key and
+	// format to another.
+	TranslatePINBlock(ctx context.Context, req PINTranslation) ([]byte, error)
+}
+
+// PINVerification is a Visa PVV PIN verification request.
+type PINVerification struct {
+	// PINKey enciphers PINBlock.
+	PINKey   KeyRef
+	PINBlock []byte
+	Format   pinblock.Format
+	PAN      string
+	// PVK is the PIN verification key pair, PVKI its index (0-6) and PVV
+	// the 4 digit verification value on file.
+	PVK  KeyRef
+	PVKI int
+	PVV  string
+}
+
+// PINTranslation is a PIN block translation request.
+type PINTranslation struct {
+	PINBlock   []byte
+	PAN        string
+	FromKey    KeyRef
+	FromFormat pinblock.Format
+	ToKey      KeyRef
+	ToFormat   pinblock.Format
+}
+
+// MACAlgorithm computes a MAC with a clear key, e.g. the algorithms of the
+// mac package.
+type MACAlgorithm interface {
+	MAC(data []byte) ([]byte, error)
+}
+
+// MACer adapts an HSM key to iso8583.MACer, so the engine MAC hooks compute
+// MACs on the HSM.
+type MACer struct {
+	HSM HSM
+	Key KeyRef
+}
+
+// MAC implements iso8583.MACer.
+func (m MACer) MAC(data []byte) ([]byte, error) {
+	return m.HSM.GenerateMAC(context.Background(), m.Key, data)
+}
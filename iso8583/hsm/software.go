@@ -0,0 +1,211 @@
+package hsm
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583/mac"
+	"github.com/randyardiansyah25/go-iso8583/iso8583/pinblock"
+)
+
+// Software is an HSM holding clear keys in memory, for tests and
+// development. It is safe for concurrent use.
+type Software struct {
+	// NewMAC creates the MAC algorithm for a key. Defaults to ISO 9797-1
+	// algorithm 3 with padding method 1 for 16 byte keys and algorithm 1
+	// otherwise.
+	NewMAC func(key []byte) (MACAlgorithm, error)
+
+	mu   sync.RWMutex
+	keys map[KeyRef][]byte
+}
+
+// NewSoftware creates an empty software HSM.
+func NewSoftware() *Software {
+	return &Software{keys: make(map[KeyRef][]byte)}
+}
+
+// SetKey stores a clear key under name, replacing any previous key.
+func (s *Software) SetKey(name KeyRef, key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		s.keys = make(map[KeyRef][]byte)
+	}
+	s.keys[name] = append([]byte(nil), key...)
+}
+
+func (s *Software) key(name KeyRef) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[name]
+	if !ok {
+		return nil, fmt.Errorf("hsm: unknown key %q", name)
+	}
+	return key, nil
+}
+
+// ImportKey deciphers a key received under the zone master key zmk (3DES
+// ECB), checks it against checkValue when given and stores it under name.
+// It backs key exchange, see iso8583.KeyExchange.
+func (s *Software) ImportKey(name, zmk KeyRef, encrypted, checkValue []byte) error {
+	kek, err := s.key(zmk)
+	if err != nil {
+		return err
+	}
+	c, err := tripleDES(kek)
+	if err != nil {
+		return err
+	}
+	if len(encrypted) == 0 || len(encrypted)%c.BlockSize() != 0 {
+		return fmt.Errorf("hsm: invalid key length %d", len(encrypted))
+	}
+
+	key := make([]byte, len(encrypted))
+	for i := 0; i < len(key); i += c.BlockSize() {
+		c.Decrypt(key[i:], encrypted[i:])
+	}
+	if len(checkValue) > 0 {
+		kcv, err := CheckValue(key)
+		if err != nil {
+			return err
+		}
+		if len(checkValue) > len(kcv) || subtle.ConstantTimeCompare(kcv[:len(checkValue)], checkValue) != 1 {
+			return ErrVerificationFailed
+		}
+	}
+
+	s.SetKey(name, key)
+	return nil
+}
+
+// CheckValue returns the key check value of a DES/3DES key: zeros
+// enciphered under the key. Check values usually carry the first 3 bytes.
+func CheckValue(key []byte) ([]byte, error) {
+	c, err := tripleDES(key)
+	if err != nil {
+		return nil, err
+	}
+	kcv := make([]byte, c.BlockSize())
+	c.Encrypt(kcv, kcv)
+	return kcv, nil
+}
+
+// GenerateMAC implements HSM.
+func (s *Software) GenerateMAC(_ context.Context, name KeyRef, data []byte) ([]byte, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var m MACAlgorithm
+	switch {
+	case s.NewMAC != nil:
+		m, err = s.NewMAC(key)
+	case len(key) == 16:
+		m, err = mac.NewAlgorithm3(key, mac.Padding1)
+	default:
+		m, err = mac.NewAlgorithm1(key, mac.Padding1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.MAC(data)
+}
+
+// VerifyPIN implements HSM.
+func (s *Software) VerifyPIN(_ context.Context, req PINVerification) error {
+	pinKey, err := s.key(req.PINKey)
+	if err != nil {
+		return err
+	}
+	pvk, err := s.key(req.PVK)
+	if err != nil {
+		return err
+	}
+
+	pin, err := pinblock.Decrypt(req.Format, req.PINBlock, req.PAN, pinKey)
+	if err != nil {
+		return err
+	}
+	pvv, err := VisaPVV(pvk, req.PAN, req.PVKI, pin)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(pvv), []byte(req.PVV)) != 1 {
+		return ErrVerificationFailed
+	}
+	return nil
+}
+
+// TranslatePINBlock implements HSM.
+func (s *Software) TranslatePINBlock(_ context.Context, req PINTranslation) ([]byte, error) {
+	fromKey, err := s.key(req.FromKey)
+	if err != nil {
+		return nil, err
+	}
+	toKey, err := s.key(req.ToKey)
+	if err != nil {
+		return nil, err
+	}
+	return pinblock.Translate(req.PINBlock, req.PAN, req.FromFormat, fromKey, req.ToFormat, toKey)
+}
+
+// VisaPVV computes the Visa PIN verification value: the transformed
+// security parameter (11 PAN digits before the check digit, PVKI and the
+// first 4 PIN digits) is enciphered under the 16 byte PVK pair and
+// decimalized.
+func VisaPVV(pvk []byte, pan string, pvki int, pin string) (string, error) {
+	if len(pvk) != 16 {
+		return "", fmt.Errorf("hsm: invalid PVK length %d", len(pvk))
+	}
+	if len(pan) < 12 || len(pin) < 4 || pvki < 0 || pvki > 9 {
+		return "", fmt.Errorf("hsm: invalid PVV input")
+	}
+
+	tsp := fmt.Sprintf("%s%d%s", pan[len(pan)-12:len(pan)-1], pvki, pin[:4])
+	block, err := hex.DecodeString(tsp)
+	if err != nil {
+		return "", err
+	}
+	c, err := tripleDES(pvk)
+	if err != nil {
+		return "", err
+	}
+	c.Encrypt(block, block)
+
+	result := strings.ToUpper(hex.EncodeToString(block))
+	var pvv strings.Builder
+	for i := 0; i < len(result) && pvv.Len() < 4; i++ {
+		if result[i] <= '9' {
+			pvv.WriteByte(result[i])
+		}
+	}
+	for i := 0; i < len(result) && pvv.Len() < 4; i++ {
+		if result[i] > '9' {
+			pvv.WriteByte(result[i] - 'A' + '0')
+		}
+	}
+	return pvv.String(), nil
+}
+
+// tripleDES creates a DES cipher for an 8 byte key and a 3DES cipher for a
+// 16 or 24 byte key.
+func tripleDES(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 8:
+		return des.NewCipher(key)
+	case 16:
+		return des.NewTripleDESCipher(append(append([]byte(nil), key...), key[:8]...))
+	case 24:
+		return des.NewTripleDESCipher(key)
+	default:
+		return nil, fmt.Errorf("hsm: invalid key length %d", len(key))
+	}
+}
@@ -0,0 +1,66 @@
+package hsm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVisaPVV(t *testing.T) {
+	pvk := unhex(t, "0123456789ABCDEFFEDCBA9876543210")
+	// TSP 4567890123411234 terenkripsi 189E41ACA69078E5, empat digit pertama
+	pvv, err := VisaPVV(pvk, "4123456789012345", 1, "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pvv != "1894" {
+		t.Fatalf("PVV = %s, want 1894", pvv)
+	}
+
+	if _, err := VisaPVV(pvk[:8], "4123456789012345", 1, "1234"); err == nil {
+		t.Error("8 byte PVK accepted")
+	}
+	if _, err := VisaPVV(pvk, "4123456789012345", 10, "1234"); err == nil {
+		t.Error("PVKI 10 accepted")
+	}
+}
+
+func TestImportKey(t *testing.T) {
+	const zmk KeyRef = "ZMK"
+	clear := unhex(t, "A1B2C3D4E5F60718293A4B5C6D7E8F90")
+	encrypted := unhex(t, "FD7AF640961CDB8B5CCFBE13B358FE6E")
+
+	s := NewSoftware()
+	s.SetKey(zmk, unhex(t, "0123456789ABCDEFFEDCBA9876543210"))
+
+	if err := s.ImportKey("ZPK", zmk, encrypted, unhex(t, "76CDB5")); err != nil {
+		t.Fatal(err)
+	}
+	key, err := s.key("ZPK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, clear) {
+		t.Fatalf("imported key %X, want %X", key, clear)
+	}
+
+	if err := s.ImportKey("ZAK", zmk, encrypted, unhex(t, "76CDB6")); !errors.Is(err, ErrVerificationFailed) {
+		t.Fatalf("wrong check value: got %v, want ErrVerificationFailed", err)
+	}
+	if _, err := s.key("ZAK"); err == nil {
+		t.Fatal("key stored despite a wrong check value")
+	}
+	if err := s.ImportKey("ZAK", zmk, encrypted[:5], nil); err == nil {
+		t.Fatal("truncated key cryptogram accepted")
+	}
+}
@@ -0,0 +1,235 @@
+package hsm
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583/pinblock"
+)
+
+// Thales is a sample client for Thales payShield 9000 style host commands
+// over TCP. Every command is framed with a 2 byte big-endian length and
+// starts with a header the HSM echoes back. Only the commands backing the
+// HSM interface are implemented: M6 (generate MAC), EC (verify interchange
+// PIN, Visa PVV) and CC (translate PIN block from ZPK to ZPK). Key
+// references are the key cryptograms under the LMK, scheme prefix included.
+//
+// Commands are sent one at a time over a single connection.
+type Thales struct {
+	Address string
+	// Timeout bounds dialing and each command when the context has no
+	// earlier deadline. Defaults to 5 seconds.
+	Timeout time.Duration
+	// HeaderLen is the length of the message header. Defaults to 4.
+	HeaderLen int
+
+	mu   sync.Mutex
+	conn net.Conn
+	seq  int
+}
+
+// NewThales creates a client for the HSM at address. The connection is
+// dialed on the first command.
+func NewThales(address string, timeout time.Duration) *Thales {
+	return &Thales{Address: address, Timeout: timeout}
+}
+
+// ThalesError is an error code returned by the HSM.
+type ThalesError struct {
+	Command string
+	Code    string
+}
+
+func (e *ThalesError) Error() string {
+	return fmt.Sprintf("hsm: %s returned error code %s", e.Command, e.Code)
+}
+
+// thalesFormats maps PIN block formats to Thales format codes.
+var thalesFormats = map[pinblock.Format]string{
+	pinblock.Format0: "01",
+	pinblock.Format1: "05",
+	pinblock.Format3: "47",
+	pinblock.Format4: "48",
+}
+
+// GenerateMAC implements HSM with the M6 command: binary input, ISO 9797-1
+// algorithm 3, padding method 1 and a 16 hex digit MAC under a ZAK.
+func (t *Thales) GenerateMAC(ctx context.Context, key KeyRef, data []byte) ([]byte, error) {
+	message := strings.ToUpper(hex.EncodeToString(data))
+	body := fmt.Sprintf("01131008%s%04X%s", key, len(data), message)
+	resp, err := t.Command(ctx, "M6", body)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 16 {
+		return nil, errors.New("hsm: short M7 response")
+	}
+	return hex.DecodeString(resp[:16])
+}
+
+// VerifyPIN implements HSM with the EC command.
+func (t *Thales) VerifyPIN(ctx context.Context, req PINVerification) error {
+	format, ok := thalesFormats[req.Format]
+	if !ok {
+		return pinblock.ErrFormat
+	}
+	account, err := accountNumber(req.PAN)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("%s%s%X%s%s%d%s", req.PINKey, req.PVK, req.PINBlock, format, account, req.PVKI, req.PVV)
+	_, err = t.Command(ctx, "EC", body)
+	var terr *ThalesError
+	if errors.As(err, &terr) && terr.Code == "01" {
+		return ErrVerificationFailed
+	}
+	return err
+}
+
+// TranslatePINBlock implements HSM with the CC command.
+func (t *Thales) TranslatePINBlock(ctx context.Context, req PINTranslation) ([]byte, error) {
+	from, ok := thalesFormats[req.FromFormat]
+	if !ok {
+		return nil, pinblock.ErrFormat
+	}
+	to, ok := thalesFormats[req.ToFormat]
+	if !ok {
+		return nil, pinblock.ErrFormat
+	}
+	account, err := accountNumber(req.PAN)
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("%s%s12%X%s%s%s", req.FromKey, req.ToKey, req.PINBlock, from, to, account)
+	resp, err := t.Command(ctx, "CC", body)
+	if err != nil {
+		return nil, err
+	}
+	// PIN length (2N), PIN block, format code (2N)
+	if len(resp) < 4 {
+		return nil, errors.New("hsm: short CD response")
+	}
+	return hex.DecodeString(resp[2 : len(resp)-2])
+}
+
+// Command sends a host command and returns the response data following the
+// response code and error code. A non-zero error code is returned as a
+// *ThalesError.
+func (t *Thales) Command(ctx context.Context, code, body string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		dialer := &net.Dialer{Timeout: t.timeout()}
+		conn, err := dialer.DialContext(ctx, "tcp", t.Address)
+		if err != nil {
+			return "", err
+		}
+		t.conn = conn
+	}
+
+	deadline := time.Now().Add(t.timeout())
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = t.conn.SetDeadline(deadline)
+
+	t.seq = (t.seq + 1) % pow10(t.headerLen())
+	header := fmt.Sprintf("%0*d", t.headerLen(), t.seq)
+	resp, err := t.roundTrip(header + code + body)
+	if err != nil {
+		// Koneksi tidak bisa dipakai lagi setelah error I/O
+		_ = t.conn.Close()
+		t.conn = nil
+		return "", err
+	}
+
+	if len(resp) < t.headerLen()+4 || resp[:t.headerLen()] != header {
+		return "", errors.New("hsm: unexpected response header")
+	}
+	resp = resp[t.headerLen():]
+	if resp[:2] != responseCode(code) {
+		return "", fmt.Errorf("hsm: unexpected response code %s to %s", resp[:2], code)
+	}
+	if resp[2:4] != "00" {
+		return "", &ThalesError{Command: code, Code: resp[2:4]}
+	}
+	return resp[4:], nil
+}
+
+// Close closes the connection to the HSM.
+func (t *Thales) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+func (t *Thales) roundTrip(command string) (string, error) {
+	frame := binary.BigEndian.AppendUint16(nil, uint16(len(command)))
+	frame = append(frame, command...)
+	if _, err := t.conn.Write(frame); err != nil {
+		return "", err
+	}
+
+	var size [2]byte
+	if _, err := io.ReadFull(t.conn, size[:]); err != nil {
+		return "", err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(t.conn, resp); err != nil {
+		return "", err
+	}
+	return string(resp), nil
+}
+
+func (t *Thales) timeout() time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+	return 5 * time.Second
+}
+
+func (t *Thales) headerLen() int {
+	if t.HeaderLen > 0 {
+		return t.HeaderLen
+	}
+	return 4
+}
+
+// responseCode returns the response code of a command: the second letter
+// incremented, e.g. CC -> CD.
+func responseCode(code string) string {
+	return code[:1] + string(code[1]+1)
+}
+
+// accountNumber returns the 12 rightmost PAN digits excluding the check
+// digit.
+func accountNumber(pan string) (string, error) {
+	if len(pan) < 13 {
+		return "", pinblock.ErrPAN
+	}
+	return pan[len(pan)-13 : len(pan)-1], nil
+}
+
+func pow10(n int) int {
+	p := 1
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
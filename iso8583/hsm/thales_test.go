@@ -0,0 +1,117 @@
+package hsm
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583/pinblock"
+)
+
+// fakeThales answers every framed command read from conn with reply, given
+// the command without its header. It reports the commands it received.
+func fakeThales(conn net.Conn, reply func(header, command string) string) <-chan string {
+	received := make(chan string, 8)
+	go func() {
+		defer close(received)
+		for {
+			var size [2]byte
+			if _, err := io.ReadFull(conn, size[:]); err != nil {
+				return
+			}
+			command := make([]byte, binary.BigEndian.Uint16(size[:]))
+			if _, err := io.ReadFull(conn, command); err != nil {
+				return
+			}
+			received <- string(command)
+			resp := reply(string(command[:4]), string(command[4:]))
+			frame := binary.BigEndian.AppendUint16(nil, uint16(len(resp)))
+			if _, err := conn.Write(append(frame, resp...)); err != nil {
+				return
+			}
+		}
+	}()
+	return received
+}
+
+func pipeThales(t *testing.T, reply func(header, command string) string) (*Thales, <-chan string) {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+	hsm := &Thales{conn: client}
+	t.Cleanup(func() { _ = hsm.Close() })
+	return hsm, fakeThales(server, reply)
+}
+
+func TestThalesGenerateMAC(t *testing.T) {
+	hsm, received := pipeThales(t, func(header, command string) string {
+		return header + "M700" + "0123456789ABCDEF"
+	})
+	mac, err := hsm.GenerateMAC(context.Background(), "U1234", []byte{0x02, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ToUpper(hex.EncodeToString(mac)); got != "0123456789ABCDEF" {
+		t.Fatalf("MAC = %s", got)
+	}
+	// Header 4 digit, kode M6, mode/format/algoritma, key, panjang dan data
+	if got, want := <-received, "0001M601131008U123400020200"; got != want {
+		t.Fatalf("command %q, want %q", got, want)
+	}
+}
+
+func TestThalesErrorCode(t *testing.T) {
+	hsm, _ := pipeThales(t, func(header, command string) string {
+		return header + "ED01"
+	})
+	err := hsm.VerifyPIN(context.Background(), PINVerification{
+		PINKey:   "UZPK",
+		PVK:      "UPVK",
+		PINBlock: []byte{0x04, 0x12, 0xAC, 0x89, 0xAB, 0xCD, 0xEF, 0x67},
+		Format:   pinblock.Format0,
+		PAN:      "43219876543210987",
+		PVKI:     1,
+		PVV:      "1894",
+	})
+	if !errors.Is(err, ErrVerificationFailed) {
+		t.Fatalf("got %v, want ErrVerificationFailed", err)
+	}
+}
+
+func TestThalesResponseChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply func(header, command string) string
+		want  string
+	}{
+		{"wrong header", func(header, command string) string { return "9999ND00" }, "unexpected response header"},
+		{"wrong response code", func(header, command string) string { return header + "NE00" }, "unexpected response code"},
+		{"short response", func(header, command string) string { return header + "N" }, "unexpected response header"},
+		{"error code", func(header, command string) string { return header + "ND15" }, "error code 15"},
+	}
+	for _, tt := range tests {
+		hsm, _ := pipeThales(t, tt.reply)
+		_, err := hsm.Command(context.Background(), "NC", "")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	hsm, _ := pipeThales(t, func(header, command string) string { return header + "ND15" })
+	_, err := hsm.Command(context.Background(), "NC", "")
+	var terr *ThalesError
+	if !errors.As(err, &terr) || terr.Command != "NC" || terr.Code != "15" {
+		t.Fatalf("got %#v, want ThalesError NC 15", err)
+	}
+
+	hsm, _ = pipeThales(t, func(header, command string) string { return header + "ND00" + "007" })
+	resp, err := hsm.Command(context.Background(), "NC", "")
+	if err != nil || resp != "007" {
+		t.Fatalf("Command = %q, %v", resp, err)
+	}
+}
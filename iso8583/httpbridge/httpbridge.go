@@ -0,0 +1,195 @@
+// Package httpbridge fronts an ISO 8583 host with a JSON over HTTP API:
+// a request carries the fields of a message as a JSON object, is forwarded
+// through an ISO client and the parsed response comes back as JSON.
+//
+//	client := iso8583.NewClient("switch:7001", 30)
+//	...
+//	bridge := httpbridge.New(client, packager)
+//	http.Handle("POST /transactions", bridge)
+//
+// Messages are JSON objects keyed "mti", "header", "trailer" and field
+// numbers, the format of the iso8583 command's parse -json:
+//
+//	{"mti": "0200", "2": "4111111111111111", "3": "000000", "4": "000000001000", "11": "000001"}
+package httpbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+)
+
+// maxBody caps the size of a request body.
+const maxBody = 64 << 10
+
+// Sender forwards a request and returns its response, e.g. an
+// *iso8583.ISOClient or an *iso8583.Pool.
+type Sender interface {
+	Send(iso iso8583.ISO8583Object) (iso8583.ISO8583Object, error)
+}
+
+// Bridge is an http.Handler forwarding JSON messages to an ISO 8583 host.
+type Bridge struct {
+	Sender Sender
+	// Packager composes the requests. When nil the spec loaded by
+	// iso8583.Load is used.
+	Packager *iso8583.Packager
+	// STAN, when set, fills DE 11 of requests that lack it.
+	STAN *iso8583.STANGenerator
+	// Unmasked returns responses as they are. By default their sensitive
+	// fields (PAN, track data, PIN block) are redacted, see
+	// iso8583.ISO8583Object.Masked.
+	Unmasked bool
+	// Logger receives forwarding errors. Defaults to slog.Default.
+	Logger *slog.Logger
+}
+
+// New creates a bridge sending through sender with the spec of packager.
+func New(sender Sender, packager *iso8583.Packager) *Bridge {
+	return &Bridge{Sender: sender, Packager: packager}
+}
+
+// ServeHTTP forwards the JSON message in the body of a POST request and
+// writes the response message as JSON. Failures are answered with
+// {"error": "..."}: 400 for a message that does not decode or compose, 504
+// when the host does not answer in time and 502 when it cannot be reached.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	req, err := b.newMessage()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := Decode(http.MaxBytesReader(w, r.Body, maxBody), req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if b.STAN != nil && !req.HasField(11) {
+		stan, err := b.STAN.Next()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		req.SetField(11, stan)
+	}
+	// Compose dulu supaya kesalahan field jadi 400, bukan kesalahan host
+	if _, err := req.ComposeBytes(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := b.Sender.Send(req)
+	if err != nil {
+		b.log().Error("forward failed", "mti", req.GetMTI(), "stan", req.GetField(11), "err", err)
+		writeError(w, sendStatus(err), err)
+		return
+	}
+	if !b.Unmasked {
+		resp = resp.Masked()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Encode(resp))
+}
+
+func (b *Bridge) newMessage() (iso8583.ISO8583Object, error) {
+	if b.Packager != nil {
+		return b.Packager.NewMessage(), nil
+	}
+	return iso8583.NewISO8583()
+}
+
+// log returns Logger, or slog.Default when none is set.
+func (b *Bridge) log() *slog.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
+	return slog.Default()
+}
+
+// sendStatus maps a Send error to an HTTP status.
+func sendStatus(err error) int {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, iso8583.ErrResponseTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, iso8583.ErrClientClosed), errors.Is(err, iso8583.ErrNoConnection), errors.As(err, &netErr):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// Encode returns the JSON form of iso: the MTI, header, trailer and every
+// field keyed by its number.
+func Encode(iso iso8583.ISO8583Object) map[string]string {
+	fields := map[string]string{"mti": iso.GetMTI()}
+	if header := iso.GetHeader(); header != "" {
+		fields["header"] = header
+	}
+	if trailer := iso.GetTrailer(); trailer != "" {
+		fields["trailer"] = trailer
+	}
+	for _, field := range iso.Fields() {
+		if field > 1 {
+			fields[strconv.Itoa(field)] = iso.GetField(field)
+		}
+	}
+	return fields
+}
+
+// Decode sets iso from the JSON form read from r, see Encode. Values may be
+// strings or numbers.
+func Decode(r io.Reader, iso iso8583.ISO8583Object) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	if len(fields) == 0 {
+		return errors.New("invalid message: no fields")
+	}
+	for key, raw := range fields {
+		var value string
+		switch v := raw.(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		default:
+			return fmt.Errorf("invalid message: %s must be a string or number", key)
+		}
+		switch strings.ToLower(key) {
+		case "mti":
+			iso.SetMTI(value)
+		case "header":
+			iso.SetHeader(value)
+		case "trailer":
+			iso.SetTrailer(value)
+		default:
+			field, err := strconv.Atoi(key)
+			if err != nil || field < 2 {
+				return fmt.Errorf("invalid message: unknown key %q", key)
+			}
+			iso.SetField(field, value)
+		}
+	}
+	return nil
+}
@@ -1,138 +1,411 @@
 package iso8583
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
-
-	"gopkg.in/yaml.v3"
+	"sync"
+	"time"
 )
 
 const DefaultSpecFile string = "isopackager.yml"
 
-var isoConfig map[int]FieldConfig
-
+// ISO8583Object is a single ISO 8583 message. The implementations returned by
+// NewISO8583 and Packager.NewMessage are safe for concurrent use.
 type ISO8583Object interface {
 	Parse(message string) error
+	ParseBytes(message []byte) error
+	ParseWithOptions(message []byte, opts ParseOptions) error
 	ComposeMessage() (string, error)
+	ComposeBytes() ([]byte, error)
 	GetField(index int) string
+	GetFieldByName(name string) (string, error)
 	GetMTI() string
 	SetField(index int, val any)
+	SetFieldByName(name string, val any) error
+	// SetMTI sets the MTI. An MTI that is not 4 digits fails ComposeBytes
+	// and Validate with ErrInvalidMTI.
 	SetMTI(val string)
+	// SetMTIChecked sets the MTI, failing with ErrInvalidMTI instead when
+	// it is not 4 digits.
+	SetMTIChecked(val string) error
 	Clear()
+	GetHeader() string
+	SetHeader(val string)
+	GetTrailer() string
+	SetTrailer(val string)
 	PrettyPrint() string
+	PrettyPrintWithOptions(opts PrintOptions) string
+	Validate() error
+	ValidateProfile() error
+	Warnings() []error
+	Clone() ISO8583Object
+	Masked() ISO8583Object
+	SetEmptyField(index int)
+	GetFieldBytes(index int) []byte
+	SetFieldBytes(index int, val []byte)
+	UnsetField(index int)
+	HasField(index int) bool
+	Fields() []int
+	GetInt(index int) (int64, error)
+	GetAmount(index int) (int64, error)
+	SetAmount(index int, minorUnits int64) error
+	GetTime(index int) (time.Time, error)
+	SetTime(index int, t time.Time) error
+	SetTransmissionTime(t time.Time) error
+	GetLocalTime() (time.Time, error)
+	SetLocalTime(t time.Time) error
+	GetSubField(index, sub int) (string, error)
+	SetSubField(index, sub int, val any) error
 }
 
 type FieldConfig struct {
-	ContentType string `yaml:"ContentType"`
-	Label       string `yaml:"Label"`
-	LenType     string `yaml:"LenType"`
-	MaxLen      int    `yaml:"MaxLen"`
+	ContentType string `yaml:"ContentType" json:"ContentType"`
+	Label       string `yaml:"Label" json:"Label"`
+	LenType     string `yaml:"LenType" json:"LenType"`
+	MaxLen      int    `yaml:"MaxLen" json:"MaxLen"`
+	Encoding    string `yaml:"Encoding" json:"Encoding"`
+	// Pad (left, right or none) and PadChar control how fixed fields shorter
+	// than MaxLen are filled, see padding for the defaults.
+	Pad     string `yaml:"Pad" json:"Pad"`
+	PadChar string `yaml:"PadChar" json:"PadChar"`
+
+	// SubFieldFormat and SubFields describe the layout of composite fields
+	// such as DE 48 or DE 62, see GetSubField. TagLen is the tag width for
+	// the tagged format.
+	SubFieldFormat string              `yaml:"SubFieldFormat" json:"SubFieldFormat"`
+	TagLen         int                 `yaml:"TagLen" json:"TagLen"`
+	SubFields      map[int]FieldConfig `yaml:"SubFields" json:"SubFields"`
+
+	// Codec names a FieldCodec registered with RegisterCodec that encodes
+	// this field instead of Encoding, e.g. CodecBCD. Not used for the MTI,
+	// the bitmap or subfields.
+	Codec string `yaml:"Codec" json:"Codec"`
+	codec FieldCodec
+}
+
+// decodeText converts wire bytes of this field to its ASCII form.
+func (f FieldConfig) decodeText(b []byte) string {
+	if f.Encoding == EncodingEBCDIC {
+		return string(EBCDICToASCII(b))
+	}
+	return string(b)
+}
+
+// encodeText converts the ASCII form of this field to wire bytes.
+func (f FieldConfig) encodeText(s []byte) []byte {
+	if f.Encoding == EncodingEBCDIC {
+		return ASCIIToEBCDIC(s)
+	}
+	return s
 }
 
+// isoObject is safe for concurrent use; every method takes mu.
 type isoObject struct {
+	mu sync.RWMutex
+
 	MTI        string
 	Bitmap     string
 	isoElement map[int]string
+	packager   *Packager
+	warnings   []error
+	// header dan trailer di luar payload ISO, lihat FrameConfig
+	header  string
+	trailer string
 }
 
+// Load reads specFile and makes it the package-wide default spec used by
+// NewISO8583 and the engine. JSON specs are detected by the .json extension.
 func Load(specFile string) (er error) {
-	data, er := os.ReadFile(specFile)
+	packager, er := LoadSpec(specFile)
 	if er != nil {
 		return er
 	}
+	defaultPackager.Store(packager)
+	return
+}
 
-	isoConfig = make(map[int]FieldConfig)
-	if er := yaml.Unmarshal(data, &isoConfig); er != nil {
+// LoadJSON is like Load but always decodes specFile as JSON.
+func LoadJSON(specFile string) (er error) {
+	packager, er := LoadSpecJSON(specFile)
+	if er != nil {
 		return er
 	}
+	defaultPackager.Store(packager)
+	return
+}
 
+// LoadFromReader is like Load but reads the spec from r.
+func LoadFromReader(r io.Reader) (er error) {
+	packager, er := LoadSpecFromReader(r)
+	if er != nil {
+		return er
+	}
+	defaultPackager.Store(packager)
+	return
+}
+
+// LoadFromBytes is like Load but decodes the spec from data.
+func LoadFromBytes(data []byte) (er error) {
+	packager, er := LoadSpecFromBytes(data)
+	if er != nil {
+		return er
+	}
+	defaultPackager.Store(packager)
 	return
 }
 
+// NewISO8583 creates an empty message bound to the spec loaded by Load.
 func NewISO8583() (ISO8583Object, error) {
-	if isoConfig == nil {
+	packager := defaultPackager.Load()
+	if packager == nil {
 		return nil, errors.New("load iso 8583 spesification first")
 	}
 
-	return &isoObject{
-		isoElement: make(map[int]string, 0),
-	}, nil
+	return packager.NewMessage(), nil
 }
 
 func (p *isoObject) Parse(message string) error {
-	// parsedData := make(map[int]string)
+	return p.ParseBytes([]byte(message))
+}
+
+// ParseBytes parses a raw message, keeping non-printable bytes (binary bitmap,
+// BCD or packed fields) intact.
+func (p *isoObject) ParseBytes(message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.parse(message, ParseOptions{})
+}
+
+// ParseWithOptions implements ISO8583Object. See ParseOptions.
+func (p *isoObject) ParseWithOptions(message []byte, opts ParseOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.parse(message, opts)
+}
+
+func (p *isoObject) parse(message []byte, opts ParseOptions) error {
 	pos := 0
+	p.warnings = nil
+
+	message, err := p.unframe(message)
+	if err != nil {
+		return err
+	}
 
 	// Parse MTI
-	mtiConfig, ok := isoConfig[0]
+	mtiConfig, ok := p.packager.spec(0)
 	if !ok {
 		return errors.New("MTI configuration missing")
 	}
-	p.isoElement[0] = message[:mtiConfig.MaxLen]
+	if len(message) < mtiConfig.MaxLen {
+		return newParseError(0, message, pos, mtiConfig.MaxLen, errMessageTruncated)
+	}
+	p.isoElement[0] = mtiConfig.decodeText(message[:mtiConfig.MaxLen])
+	if err := mtiConfig.checkValue(p.isoElement[0]); err != nil {
+		if err := p.parseViolation(opts, newParseError(0, message, pos, mtiConfig.MaxLen, err)); err != nil {
+			return err
+		}
+	}
+	opts.mark(0, pos, pos+mtiConfig.MaxLen)
 	pos += mtiConfig.MaxLen
 
 	// Parse Bitmap
-	bitmapConfig, ok := isoConfig[1]
-	if !ok {
+	if _, ok := p.packager.spec(1); !ok {
 		return errors.New("bitmap configuration missing")
 	}
-	bitmapHex := message[pos : pos+bitmapConfig.MaxLen]
-	p.isoElement[1] = bitmapHex
-	bitmapBytes, err := hex.DecodeString(bitmapHex)
+	bitmapBytes, next, err := p.packager.readBitmap(message, pos)
 	if err != nil {
-		return err
+		return newParseError(1, message, pos, p.packager.bitmapWidth(), err)
 	}
-	pos += bitmapConfig.MaxLen
+	opts.mark(1, pos, next)
+	pos = next
+	p.isoElement[1] = strings.ToUpper(hex.EncodeToString(bitmapBytes))
 
 	// Process bitmap p.isoElement
-	for i := 2; i <= 128; i++ {
+	for i := 2; i <= len(bitmapBytes)*8; i++ {
 		if (bitmapBytes[(i-1)/8] & (1 << (7 - ((i - 1) % 8)))) > 0 {
-			fieldConfig, exists := isoConfig[i]
+			if i == 65 && p.packager.hasTertiaryBitmap() {
+				// DE 65 membawa tertiary bitmap untuk field 129-192
+				tertiary, next, err := p.packager.readBitmapBlock(message, pos)
+				if err != nil {
+					return newParseError(65, message, pos, p.packager.bitmapWidth(), err)
+				}
+				opts.mark(65, pos, next)
+				pos = next
+				bitmapBytes = append(bitmapBytes, tertiary...)
+				p.isoElement[1] = strings.ToUpper(hex.EncodeToString(bitmapBytes))
+				continue
+			}
+
+			fs, exists := p.packager.spec(i)
 			if !exists {
-				return fmt.Errorf("field %d configuration missing", i)
+				return p.parseFailure(opts, newParseError(i, message, pos, 0, errors.New("configuration missing")))
+			}
+
+			start := pos
+			if codec := fs.codec; codec != nil {
+				length, read, err := codec.DecodeLength(fs.FieldConfig, message[pos:])
+				if err != nil {
+					return p.parseFailure(opts, newParseError(i, message, start, 0, err))
+				}
+				pos += read
+				if length > fs.MaxLen {
+					perr := newParseError(i, message, start, read, fmt.Errorf("length %d exceeds MaxLen %d", length, fs.MaxLen))
+					if err := p.parseViolation(opts, perr); err != nil {
+						return err
+					}
+				}
+				raw, read, err := codec.Decode(fs.FieldConfig, message[pos:], length)
+				if err != nil {
+					return p.parseFailure(opts, newParseError(i, message, start, pos-start, err))
+				}
+				if err := fs.checkValue(string(raw)); err != nil {
+					if err := p.parseViolation(opts, newParseError(i, message, start, pos+read-start, err)); err != nil {
+						return err
+					}
+				}
+				p.isoElement[i] = string(raw)
+				pos += read
+				opts.mark(i, start, pos)
+				continue
 			}
 
-			switch fieldConfig.LenType {
-			case "fixed":
-				p.isoElement[i] = message[pos : pos+fieldConfig.MaxLen]
-				pos += fieldConfig.MaxLen
-			case "llvar":
-				length, _ := strconv.Atoi(message[pos : pos+2])
-				pos += 2
-				p.isoElement[i] = message[pos : pos+length]
-				pos += length
-			case "lllvar":
-				length, _ := strconv.Atoi(message[pos : pos+3])
-				pos += 3
-				p.isoElement[i] = message[pos : pos+length]
-				pos += length
-			default:
-				return fmt.Errorf("unsupported length type for field %d", i)
+			length := fs.MaxLen
+			if digits := fs.digits; digits > 0 {
+				if pos+digits > len(message) {
+					return p.parseFailure(opts, newParseError(i, message, start, digits, errMessageTruncated))
+				}
+				var err error
+				length, err = fs.readLength(message[pos : pos+digits])
+				if err != nil {
+					return p.parseFailure(opts, newParseError(i, message, start, digits, err))
+				}
+				pos += digits
+				if length > fs.MaxLen {
+					perr := newParseError(i, message, start, digits+fs.wireSize(length), fmt.Errorf("length %d exceeds MaxLen %d", length, fs.MaxLen))
+					if err := p.parseViolation(opts, perr); err != nil {
+						return err
+					}
+				}
+			}
+
+			length = fs.wireSize(length)
+			if pos+length > len(message) {
+				return p.parseFailure(opts, newParseError(i, message, start, pos+length-start, errMessageTruncated))
+			}
+			value, err := fs.value(message[pos : pos+length])
+			if err != nil {
+				return p.parseFailure(opts, newParseError(i, message, start, pos+length-start, err))
 			}
+			if err := fs.checkValue(value); err != nil {
+				if err := p.parseViolation(opts, newParseError(i, message, start, pos+length-start, err)); err != nil {
+					return err
+				}
+			}
+			p.isoElement[i] = value
+			pos += length
+			opts.mark(i, start, pos)
+		}
+	}
+
+	if pos < len(message) {
+		if err := p.parseViolation(opts, newParseError(-1, message, pos, 0, errors.New("unexpected trailing data"))); err != nil {
+			return err
+		}
+	}
+
+	// Format MTI hanya diperiksa di luar ParseDefault
+	for _, err := range []*FieldError{p.checkMTI(opts.Mode != ParseDefault), p.checkPAN()} {
+		if err == nil {
+			continue
+		}
+		if opts.Mode != ParseLenient {
+			return err
 		}
+		p.warnings = append(p.warnings, err)
 	}
 
 	return nil
 }
 
+// parseLength decodes a variable-length prefix, accepting digits only.
+func parseLength(prefix string) (int, error) {
+	for _, c := range prefix {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid length prefix %q", prefix)
+		}
+	}
+	return strconv.Atoi(prefix)
+}
+
 // ComposeMessage: Membuat message ISO8583 berdasarkan input field
 func (p *isoObject) ComposeMessage() (string, error) {
+	message, err := p.ComposeBytes()
+	if err != nil {
+		return "", err
+	}
+	return string(message), nil
+}
+
+// ComposeBytes: Sama seperti ComposeMessage, tetapi menghasilkan raw byte
+// sehingga field biner tidak ikut terkonversi.
+func (p *isoObject) ComposeBytes() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.compose()
+}
+
+// composePool menyimpan buffer compose supaya message berikutnya tidak
+// perlu alokasi ulang. Buffer yang terlalu besar tidak dikembalikan.
+var composePool = sync.Pool{New: func() any { return new([]byte) }}
+
+const maxPooledBuffer = 64 * 1024
+
+func (p *isoObject) compose() ([]byte, error) {
+	buf := composePool.Get().(*[]byte)
+	message, err := p.appendFramed((*buf)[:0])
+	if cap(message) <= maxPooledBuffer {
+		*buf = message[:0]
+		defer composePool.Put(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(message), nil
+}
+
+// appendBody appends the message from the MTI to the last field to dst.
+func (p *isoObject) appendBody(dst []byte) ([]byte, error) {
 	elements := p.isoElement
 	if len(elements) == 0 {
-		return "", errors.New("iso8583 element is empty")
+		return nil, errors.New("iso8583 element is empty")
 	}
 
 	if _, ok := elements[0]; !ok {
-		return "", errors.New("MTI harus ada di field 0")
+		return nil, errors.New("MTI harus ada di field 0")
+	}
+
+	// Validasi ContentType, di mode lenient cukup dicatat sebagai warning
+	p.warnings = nil
+	if err := p.validate(); err != nil {
+		if !p.packager.Lenient {
+			return nil, err
+		}
+		p.warnings = append(p.warnings, err)
 	}
 
 	// Susun MTI
-	message := elements[0]
+	mtiSpec, ok := p.packager.spec(0)
+	if !ok {
+		return nil, errors.New("MTI configuration missing")
+	}
+	message := mtiSpec.appendText(dst, elements[0])
 
 	// Cek apakah ada field di atas 64 (butuh secondary bitmap)
 	maxField := 0
@@ -142,20 +415,40 @@ func (p *isoObject) ComposeMessage() (string, error) {
 		}
 	}
 
+	useTertiaryBitmap := maxField > 128
+	if useTertiaryBitmap && !p.packager.hasTertiaryBitmap() {
+		return nil, fmt.Errorf("config untuk field %d tidak ditemukan", maxField)
+	}
+	if maxField > 192 {
+		return nil, fmt.Errorf("field %d melebihi tertiary bitmap", maxField)
+	}
+	if _, ok := elements[65]; ok && p.packager.hasTertiaryBitmap() {
+		return nil, errors.New("field 65 dipakai untuk tertiary bitmap")
+	}
+
 	useSecondaryBitmap := maxField > 64
 	bitmapSize := 8
 	if useSecondaryBitmap {
 		bitmapSize = 16
 	}
+	if useTertiaryBitmap {
+		bitmapSize = 24
+	}
 
-	// Buat bitmap kosong
-	bitmap := make([]byte, bitmapSize)
+	// Buat bitmap kosong, di stack supaya tidak alokasi
+	var bitmapBuf [24]byte
+	bitmap := bitmapBuf[:bitmapSize]
 
 	// Set bit pertama di primary bitmap kalau ada secondary
 	if useSecondaryBitmap {
 		bitmap[0] |= 0x80 // Set bit paling kiri ke 1
 	}
 
+	// Set bit 65 kalau ada tertiary
+	if useTertiaryBitmap {
+		bitmap[8] |= 0x80
+	}
+
 	// Set active bits in bitmap
 	for field := range elements {
 		if field > 1 {
@@ -165,82 +458,219 @@ func (p *isoObject) ComposeMessage() (string, error) {
 		}
 	}
 
-	// Encode bitmap to hex (HARUS 16 byte kalau secondary aktif)
-	bitmapHex := hex.EncodeToString(bitmap)
-	message += strings.ToUpper(bitmapHex)
+	// Encode bitmap sesuai BitmapEncoding di spec (hex atau binary)
+	message = p.packager.appendBitmap(message, bitmap[:min(bitmapSize, 16)])
 
 	// Susun Data Field
-	for i := 2; i <= 128; i++ {
+	for i := 2; i <= bitmapSize*8; i++ {
+		if i == 65 && useTertiaryBitmap {
+			message = p.packager.appendBitmap(message, bitmap[16:])
+			continue
+		}
+		// Bit yang kosong dilewati tanpa lookup map
+		if bitmap[(i-1)/8]&(1<<(7-(i-1)%8)) == 0 {
+			continue
+		}
 		if value, exists := elements[i]; exists {
-			fieldConfig, ok := isoConfig[i]
+			fs, ok := p.packager.spec(i)
 			if !ok {
-				return "", fmt.Errorf("config untuk field %d tidak ditemukan", i)
+				return nil, fmt.Errorf("config untuk field %d tidak ditemukan", i)
 			}
 
-			switch fieldConfig.LenType {
-			case "fixed":
-				value = p.padValue(value, fieldConfig.MaxLen, fieldConfig.ContentType)
-				message += value
-			case "llvar":
-				length := fmt.Sprintf("%02d", len(value))
-				message += length + value
-			case "lllvar":
-				length := fmt.Sprintf("%03d", len(value))
-				message += length + value
-			default:
-				return "", fmt.Errorf("tipe panjang tidak dikenal untuk field %d", i)
+			if fs.digits > 0 && len(value) > fs.MaxLen {
+				if !p.packager.TruncateOverLength {
+					return nil, fmt.Errorf("panjang field %d (%d) melebihi MaxLen %d", i, len(value), fs.MaxLen)
+				}
+				value = value[:fs.MaxLen]
 			}
 
+			if fs.codec != nil {
+				wire, err := p.encodeWithCodec(value, fs.FieldConfig)
+				if err != nil {
+					return nil, fmt.Errorf("field %d: %w", i, err)
+				}
+				message = append(message, wire...)
+				continue
+			}
+
+			if fs.digits > 0 {
+				message = fs.appendLength(message, len(value))
+			} else if len(value) != fs.MaxLen {
+				var err error
+				value, err = p.padValue(value, fs.FieldConfig)
+				if err != nil {
+					return nil, fmt.Errorf("field %d: %w", i, err)
+				}
+			}
+			message = fs.appendValue(message, value)
+
 		}
 	}
 
 	return message, nil
 }
 
-func (p *isoObject) padValue(value string, maxLen int, contentType string) string {
+// encodeWithCodec returns the length prefix and value of a field encoded by
+// its FieldCodec.
+func (p *isoObject) encodeWithCodec(value string, f FieldConfig) ([]byte, error) {
+	if f.LenType == "fixed" {
+		var err error
+		if value, err = p.padValue(value, f); err != nil {
+			return nil, err
+		}
+	}
+	prefix, err := f.codec.EncodeLength(f, len(value))
+	if err != nil {
+		return nil, err
+	}
+	wire, err := f.codec.Encode(f, []byte(value))
+	if err != nil {
+		return nil, err
+	}
+	return append(prefix, wire...), nil
+}
+
+func (p *isoObject) padValue(value string, f FieldConfig) (string, error) {
+	maxLen := f.MaxLen
+	if len(value) == maxLen {
+		return value, nil
+	}
 	if len(value) > maxLen {
-		return value[:maxLen] // Truncate jika lebih panjang dari MaxLen
+		return value[:maxLen], nil // Truncate jika lebih panjang dari MaxLen
 	}
-	if contentType == "n" {
-		return fmt.Sprintf("%0*s", maxLen, value) // Padding 0 di kiri untuk numerik
+
+	pad, padChar := f.padding()
+	fill := strings.Repeat(padChar, maxLen-len(value))
+	switch pad {
+	case PadNone:
+		if len(value) != maxLen {
+			return "", fmt.Errorf("panjang %d tidak sama dengan MaxLen %d dan padding tidak diizinkan", len(value), maxLen)
+		}
+		return value, nil
+	case PadLeft:
+		return fill + value, nil
+	default:
+		return value + fill, nil
 	}
-	return fmt.Sprintf("%-*s", maxLen, value) // Padding spasi di kanan untuk non-numerik
 }
 
 // GetField implements ISO8583Object.
 func (p *isoObject) GetField(index int) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.isoElement[index]
 }
 
+// SetMTI implements ISO8583Object.
 func (p *isoObject) SetMTI(val string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.isoElement[0] = val
 }
 
+// SetMTIChecked implements ISO8583Object.
+func (p *isoObject) SetMTIChecked(val string) error {
+	if err := ValidateMTI(val); err != nil {
+		return &FieldError{Field: 0, Err: err}
+	}
+	p.SetMTI(val)
+	return nil
+}
+
 // GetMTI implements ISO8583Object.
 func (p *isoObject) GetMTI() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.isoElement[0]
 }
 
-// SetField implements ISO8583Object.
+// SetField implements ISO8583Object. Setting an empty value removes the
+// field, so it is left out of the bitmap; use SetEmptyField to send a field
+// that is present but empty.
 func (p *isoObject) SetField(index int, val any) {
-	p.isoElement[index] = fmt.Sprint(val)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	value := fmt.Sprint(val)
+	if value == "" {
+		delete(p.isoElement, index)
+		return
+	}
+	p.isoElement[index] = value
 }
 
-// PrintPretty implements ISO8583Object.
-func (p *isoObject) PrettyPrint() string {
-	isoBuffer := []string{}
+// SetEmptyField implements ISO8583Object. The field is flagged in the bitmap
+// with an empty value, e.g. an LLVAR sent as "00".
+func (p *isoObject) SetEmptyField(index int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.isoElement[index] = ""
+}
 
-	keys := make([]int, 0)
+// UnsetField implements ISO8583Object.
+func (p *isoObject) UnsetField(index int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.isoElement, index)
+}
+
+// HasField implements ISO8583Object.
+func (p *isoObject) HasField(index int) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.isoElement[index]
+	return ok
+}
+
+// Fields implements ISO8583Object. It returns the set field numbers in
+// ascending order, the MTI (0) included and the bitmap (1) left out since it
+// is derived from the other fields.
+func (p *isoObject) Fields() []int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.fields()
+}
+
+func (p *isoObject) fields() []int {
+	keys := make([]int, 0, len(p.isoElement))
 	for k := range p.isoElement {
-		keys = append(keys, int(k))
+		if k != 1 {
+			keys = append(keys, k)
+		}
 	}
 	sort.Ints(keys)
-	for _, k := range keys {
-		isoBuffer = append(isoBuffer, fmt.Sprintf("[%03d][%s]\n", k, p.isoElement[k]))
+	return keys
+}
+
+// PrettyPrint implements ISO8583Object. It lists the fields with their
+// Label and declared format, see PrettyPrintWithOptions.
+func (p *isoObject) PrettyPrint() string {
+	return p.PrettyPrintWithOptions(PrintOptions{})
+}
+
+// Clone implements ISO8583Object. The copy shares the packager but none of
+// the field storage, so changing one does not affect the other.
+func (p *isoObject) Clone() ISO8583Object {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	elements := make(map[int]string, len(p.isoElement))
+	for k, v := range p.isoElement {
+		elements[k] = v
+	}
+	return &isoObject{
+		MTI:        p.MTI,
+		Bitmap:     p.Bitmap,
+		isoElement: elements,
+		packager:   p.packager,
+		warnings:   append([]error(nil), p.warnings...),
+		header:     p.header,
+		trailer:    p.trailer,
 	}
-	return strings.Join(isoBuffer, "")
 }
 
 func (p *isoObject) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.isoElement = make(map[int]string, 0)
+	p.warnings = nil
 }
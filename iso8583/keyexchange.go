@@ -0,0 +1,114 @@
+package iso8583
+
+import "errors"
+
+// Default DE 70 key exchange codes: key change and new key request.
+const (
+	NetworkKeyChange  = "101"
+	NetworkKeyRequest = "161"
+)
+
+// DefaultKeyExchangeFailureCode is the DE 39 answered when OnKey rejects a
+// key (cryptographic failure).
+const DefaultKeyExchangeFailureCode = "88"
+
+var errNoKeyHandler = errors.New("key exchange has no OnKey, key not stored")
+
+// KeyChange is a working key received in a key exchange request.
+type KeyChange struct {
+	// Code is the DE 70 value of the request.
+	Code string
+	// KeyType is the value of KeyTypeField, e.g. DE 53 security control
+	// information telling a ZPK from a ZAK.
+	KeyType string
+	// Key is the new key, enciphered under the zone master key, as carried
+	// in KeyField; CheckValue is its check value when present.
+	Key        string
+	CheckValue string
+	Request    ISO8583Object
+}
+
+// KeyExchange configures the automatic handling of 08xx key exchange
+// requests. OnKey stores the new key, typically by importing it into the
+// HSM used by the MAC and PIN subsystems, see hsm.Software.ImportKey. The
+// engine answers with DE 39 "00" ("000" from ISO 8583:1993 on), or
+// FailureCode when OnKey fails or is not set, so the peer never takes a key
+// nobody stored as active.
+type KeyExchange struct {
+	// Codes are the DE 70 values handled. Defaults to NetworkKeyChange and
+	// NetworkKeyRequest.
+	Codes []string
+	// KeyField carries the key, optionally followed by CheckValueLen
+	// characters of check value. Defaults to DE 48.
+	KeyField      int
+	CheckValueLen int
+	// KeyTypeField identifies the key. Defaults to DE 53.
+	KeyTypeField int
+	FailureCode  string
+
+	OnKey func(kc KeyChange) error
+}
+
+// EnableKeyExchange turns on automatic 08xx key exchange handling.
+func (t *TCPIso8583Engine) EnableKeyExchange(kx KeyExchange) {
+	if len(kx.Codes) == 0 {
+		kx.Codes = []string{NetworkKeyChange, NetworkKeyRequest}
+	}
+	if kx.KeyField == 0 {
+		kx.KeyField = 48
+	}
+	if kx.KeyTypeField == 0 {
+		kx.KeyTypeField = 53
+	}
+	if kx.FailureCode == "" {
+		kx.FailureCode = DefaultKeyExchangeFailureCode
+	}
+	t.keyExchange = &kx
+}
+
+// handle answers iso when it is a key exchange request. It reports whether
+// iso was handled and the error OnKey returned, if any.
+func (kx *KeyExchange) handle(iso ISO8583Object) (bool, error) {
+	mti := iso.GetMTI()
+	if len(mti) != 4 || !isNetworkMTI(mti) || !isRequestMTI(mti) {
+		return false, nil
+	}
+
+	code := iso.GetField(70)
+	handled := false
+	for _, c := range kx.Codes {
+		if c == code {
+			handled = true
+			break
+		}
+	}
+	if !handled {
+		return false, nil
+	}
+
+	kc := KeyChange{
+		Code:    code,
+		KeyType: iso.GetField(kx.KeyTypeField),
+		Key:     iso.GetField(kx.KeyField),
+		Request: iso.Clone(),
+	}
+	if kx.CheckValueLen > 0 && len(kc.Key) > kx.CheckValueLen {
+		split := len(kc.Key) - kx.CheckValueLen
+		kc.Key, kc.CheckValue = kc.Key[:split], kc.Key[split:]
+	}
+
+	err := errNoKeyHandler
+	if kx.OnKey != nil {
+		err = kx.OnKey(kc)
+	}
+
+	iso.SetMTI(responseMTI(mti))
+	if err != nil {
+		iso.SetField(39, kx.FailureCode)
+	} else {
+		iso.SetField(39, approvalCode(mti))
+	}
+	// Key tidak ikut dikirim balik di response
+	iso.UnsetField(kx.KeyField)
+	return true, err
+}
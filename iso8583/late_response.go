@@ -0,0 +1,94 @@
+package iso8583
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultLateResponseWindow is how long a timed out request waits for a late
+// response when LateResponseWindow is not set.
+const DefaultLateResponseWindow = time.Minute
+
+// lateRequest is a request that timed out, kept to recognize its late
+// response.
+type lateRequest struct {
+	request ISO8583Object
+	expires time.Time
+}
+
+func (c *ISOClient) lateWindow() time.Duration {
+	if c.LateResponseWindow > 0 {
+		return c.LateResponseWindow
+	}
+	return DefaultLateResponseWindow
+}
+
+// timedOut drops the pending request of key and remembers iso for its late
+// response, then notifies OnTimeout and sends the reversal when asked to.
+// It is called with c.mu unlocked.
+func (c *ISOClient) timedOut(key string, iso ISO8583Object) {
+	now := time.Now()
+	c.mu.Lock()
+	delete(c.pending, key)
+	if c.OnLateResponse != nil {
+		if c.late == nil {
+			c.late = make(map[string]lateRequest)
+		}
+		for k, l := range c.late {
+			if now.After(l.expires) {
+				delete(c.late, k)
+			}
+		}
+		c.late[key] = lateRequest{request: iso, expires: now.Add(c.lateWindow())}
+	}
+	c.mu.Unlock()
+
+	c.log().Warn("response timeout", "address", c.Address, "mti", iso.GetMTI(), "stan", iso.GetField(11))
+	if c.OnTimeout != nil {
+		c.OnTimeout(iso)
+	}
+	if c.AutoReversal && reversible(iso.GetMTI()) {
+		go c.reverse(iso)
+	}
+}
+
+// takeLate returns the timed out request resp answers, if any. Its key may
+// already be reused by a pending request, e.g. the reversal, so the MTI has
+// to match too. It is called with c.mu locked.
+func (c *ISOClient) takeLate(key string, resp ISO8583Object) (ISO8583Object, bool) {
+	l, ok := c.late[key]
+	if !ok || responseMTI(l.request.GetMTI()) != resp.GetMTI() {
+		return nil, false
+	}
+	delete(c.late, key)
+	if time.Now().After(l.expires) {
+		return nil, false
+	}
+	return l.request, true
+}
+
+// reversible reports whether mti is an authorization or financial request.
+// A timed out advice is not reversed but repeated, which is what the SAF is
+// for.
+func reversible(mti string) bool {
+	return len(mti) == 4 && mti[2] == '0' && (mti[1] == '1' || mti[1] == '2')
+}
+
+// reverse sends the reversal of the timed out request original, through the
+// SAF when the client has one so it is not lost while the host is down.
+func (c *ISOClient) reverse(original ISO8583Object) {
+	reversal, err := BuildReversalWithOptions(original, c.ReversalOptions)
+	if err != nil {
+		c.log().Error("build reversal failed", "address", c.Address, "stan", original.GetField(11), "err", err)
+		return
+	}
+	resp, err := c.SendAdvice(reversal)
+	switch {
+	case errors.Is(err, ErrSAFQueued):
+		c.log().Warn("reversal stored for forwarding", "address", c.Address, "stan", reversal.GetField(11))
+	case err != nil:
+		c.log().Error("reversal failed", "address", c.Address, "stan", reversal.GetField(11), "err", err)
+	default:
+		c.log().Info("reversal sent", "address", c.Address, "stan", reversal.GetField(11), "rc", resp.GetField(39))
+	}
+}
@@ -0,0 +1,174 @@
+// Package mac computes message authentication codes for ISO 8583 DE 64/128:
+// ISO 9797-1 MAC algorithms 1 and 3 over DES/3DES and AES-CMAC.
+//
+// Every algorithm implements iso8583.MACer, so it can be plugged into the
+// engine MAC hooks directly.
+package mac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"fmt"
+)
+
+// Padding is an ISO 9797-1 padding method.
+type Padding int
+
+const (
+	// Padding1 appends zero bytes up to the block size, none when the data
+	// is already aligned (ISO 9797-1 method 1).
+	Padding1 Padding = iota + 1
+	// Padding2 appends 0x80 followed by zero bytes (ISO 9797-1 method 2).
+	Padding2
+)
+
+func (p Padding) pad(data []byte, blockSize int) []byte {
+	padded := append([]byte(nil), data...)
+	if p == Padding2 {
+		padded = append(padded, 0x80)
+	}
+	for len(padded)%blockSize != 0 || len(padded) == 0 {
+		padded = append(padded, 0)
+	}
+	return padded
+}
+
+// CBCMAC is an ISO 9797-1 MAC algorithm 1 or 3.
+type CBCMAC struct {
+	block   cipher.Block
+	final   cipher.Block // algorithm 3 only
+	final2  cipher.Block // algorithm 3 only
+	padding Padding
+}
+
+// NewAlgorithm1 creates ISO 9797-1 MAC algorithm 1: a CBC-MAC with DES for
+// an 8 byte key or 3DES for a 16 or 24 byte key.
+func NewAlgorithm1(key []byte, padding Padding) (*CBCMAC, error) {
+	var (
+		block cipher.Block
+		err   error
+	)
+	switch len(key) {
+	case 8:
+		block, err = des.NewCipher(key)
+	case 16:
+		block, err = des.NewTripleDESCipher(append(append([]byte(nil), key...), key[:8]...))
+	case 24:
+		block, err = des.NewTripleDESCipher(key)
+	default:
+		return nil, fmt.Errorf("mac: invalid key length %d", len(key))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CBCMAC{block: block, padding: padding}, nil
+}
+
+// NewAlgorithm3 creates ISO 9797-1 MAC algorithm 3, the ANSI X9.19 retail
+// MAC: a single DES CBC-MAC under the left key half with the last block
+// decrypted under the right half and encrypted again under the left half.
+// key must be 16 bytes.
+func NewAlgorithm3(key []byte, padding Padding) (*CBCMAC, error) {
+	if len(key) != 16 {
+		return nil, fmt.Errorf("mac: invalid key length %d", len(key))
+	}
+	left, err := des.NewCipher(key[:8])
+	if err != nil {
+		return nil, err
+	}
+	right, err := des.NewCipher(key[8:])
+	if err != nil {
+		return nil, err
+	}
+	return &CBCMAC{block: left, final: right, final2: left, padding: padding}, nil
+}
+
+// MAC returns the 8 byte MAC of data.
+func (m *CBCMAC) MAC(data []byte) ([]byte, error) {
+	bs := m.block.BlockSize()
+	padded := m.padding.pad(data, bs)
+
+	state := make([]byte, bs)
+	for i := 0; i < len(padded); i += bs {
+		for j := 0; j < bs; j++ {
+			state[j] ^= padded[i+j]
+		}
+		m.block.Encrypt(state, state)
+	}
+	if m.final != nil {
+		m.final.Decrypt(state, state)
+		m.final2.Encrypt(state, state)
+	}
+	return state, nil
+}
+
+// CMAC is AES-CMAC as specified by NIST SP 800-38B and RFC 4493.
+type CMAC struct {
+	block  cipher.Block
+	k1, k2 []byte
+}
+
+// NewCMAC creates an AES-CMAC for a 16, 24 or 32 byte key.
+func NewCMAC(key []byte) (*CMAC, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]byte, aes.BlockSize)
+	block.Encrypt(l, l)
+	k1 := shiftSubkey(l)
+	k2 := shiftSubkey(k1)
+	return &CMAC{block: block, k1: k1, k2: k2}, nil
+}
+
+// shiftSubkey doubles b in GF(2^128).
+func shiftSubkey(b []byte) []byte {
+	out := make([]byte, len(b))
+	var carry byte
+	for i := len(b) - 1; i >= 0; i-- {
+		out[i] = b[i]<<1 | carry
+		carry = b[i] >> 7
+	}
+	if carry != 0 {
+		out[len(out)-1] ^= 0x87
+	}
+	return out
+}
+
+// MAC returns the 16 byte CMAC of data. DE 64/128 carry its first 8 bytes.
+func (m *CMAC) MAC(data []byte) ([]byte, error) {
+	bs := aes.BlockSize
+	n := (len(data) + bs - 1) / bs
+	complete := n > 0 && len(data)%bs == 0
+	if n == 0 {
+		n = 1
+	}
+
+	last := make([]byte, bs)
+	if complete {
+		copy(last, data[(n-1)*bs:])
+		xor(last, m.k1)
+	} else {
+		rest := data[(n-1)*bs:]
+		copy(last, rest)
+		last[len(rest)] = 0x80
+		xor(last, m.k2)
+	}
+
+	state := make([]byte, bs)
+	for i := 0; i < n-1; i++ {
+		xor(state, data[i*bs:(i+1)*bs])
+		m.block.Encrypt(state, state)
+	}
+	xor(state, last)
+	m.block.Encrypt(state, state)
+	return state, nil
+}
+
+func xor(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,146 @@
+package mac_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+	"github.com/randyardiansyah25/go-iso8583/iso8583/mac"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// fips113 is the FIPS 113 / ANSI X9.9 sample message.
+var fips113 = []byte("7654321 Now is the time for ")
+
+func TestCBCMAC(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm int
+		key       string
+		padding   mac.Padding
+		want      string
+	}{
+		{"algorithm 1 DES padding 1", 1, "0123456789ABCDEF", mac.Padding1, "F1D30F6849312CA4"},
+		{"algorithm 1 DES padding 2", 1, "0123456789ABCDEF", mac.Padding2, "D0163999B2406DED"},
+		{"algorithm 1 3DES padding 1", 1, "0123456789ABCDEFFEDCBA9876543210", mac.Padding1, "E5E7A413C3E3F4B5"},
+		{"algorithm 3 padding 1", 3, "0123456789ABCDEFFEDCBA9876543210", mac.Padding1, "AE4B45B1B527642F"},
+		{"algorithm 3 padding 2", 3, "0123456789ABCDEFFEDCBA9876543210", mac.Padding2, "863BE25DAF06098B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newMAC := mac.NewAlgorithm1
+			if tt.algorithm == 3 {
+				newMAC = mac.NewAlgorithm3
+			}
+			m, err := newMAC(unhex(t, tt.key), tt.padding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.MAC(fips113)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := unhex(t, tt.want); !bytes.Equal(got, want) {
+				t.Fatalf("MAC = %X, want %X", got, want)
+			}
+		})
+	}
+}
+
+func TestCBCMACKeyLength(t *testing.T) {
+	if _, err := mac.NewAlgorithm1(make([]byte, 10), mac.Padding1); err == nil {
+		t.Error("algorithm 1 accepted a 10 byte key")
+	}
+	if _, err := mac.NewAlgorithm3(make([]byte, 8), mac.Padding1); err == nil {
+		t.Error("algorithm 3 accepted an 8 byte key")
+	}
+}
+
+// TestCMAC uses the AES-128 examples of RFC 4493 section 4.
+func TestCMAC(t *testing.T) {
+	const message = "6bc1bee22e409f96e93d7e117393172a" +
+		"ae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52ef" +
+		"f69f2445df4f9b17ad2b417be66c3710"
+	m, err := mac.NewCMAC(unhex(t, "2b7e151628aed2a6abf7158809cf4f3c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		length int
+		want   string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+	for _, tt := range tests {
+		got, err := m.MAC(unhex(t, message)[:tt.length])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := unhex(t, tt.want); !bytes.Equal(got, want) {
+			t.Errorf("%d byte message: CMAC = %x, want %x", tt.length, got, want)
+		}
+	}
+}
+
+func TestStampVerifyMAC(t *testing.T) {
+	m, err := mac.NewAlgorithm3(unhex(t, "0123456789ABCDEFFEDCBA9876543210"), mac.Padding1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := iso8583.MACConfig{MACer: m}
+	pk := iso8583.NewDefaultPackager()
+
+	for _, field := range []int{64, 128} {
+		iso := pk.NewMessage()
+		iso.SetMTI("0200")
+		iso.SetField(3, "000000")
+		iso.SetField(4, "000000015000")
+		iso.SetField(11, "000001")
+		iso.SetField(41, "TERM0001")
+		if field == 128 {
+			iso.SetField(102, "1234567890")
+		}
+		if err := iso8583.StampMAC(iso, cfg); err != nil {
+			t.Fatal(err)
+		}
+		if !iso.HasField(field) {
+			t.Fatalf("MAC not stamped in DE %d, fields %v", field, iso.Fields())
+		}
+		raw, err := iso.ComposeBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed := pk.NewMessage()
+		if err := parsed.ParseBytes(raw); err != nil {
+			t.Fatal(err)
+		}
+		if err := iso8583.VerifyMAC(parsed, raw, cfg); err != nil {
+			t.Fatalf("DE %d: %v", field, err)
+		}
+
+		// Mengubah satu field harus menggagalkan verifikasi
+		parsed.SetField(4, "000000099999")
+		tampered, err := parsed.ComposeBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := iso8583.VerifyMAC(parsed, tampered, cfg); !errors.Is(err, iso8583.ErrMACMismatch) {
+			t.Fatalf("DE %d: tampered message: got %v, want ErrMACMismatch", field, err)
+		}
+	}
+}
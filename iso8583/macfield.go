@@ -0,0 +1,137 @@
+package iso8583
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// MACer computes a message authentication code. The mac subpackage provides
+// ISO 9797-1 algorithm 1 and 3 and AES-CMAC implementations.
+type MACer interface {
+	MAC(data []byte) ([]byte, error)
+}
+
+var (
+	// ErrMACMissing is returned by VerifyMAC for a message without DE 64/128.
+	ErrMACMissing = errors.New("iso8583 MAC field missing")
+	// ErrMACMismatch is returned by VerifyMAC when the MAC does not match.
+	ErrMACMismatch = errors.New("iso8583 MAC mismatch")
+)
+
+// DefaultMACFailureCode is the DE 39 the engine answers a request with when
+// its MAC does not verify (security violation).
+const DefaultMACFailureCode = "63"
+
+// MACConfig describes how messages are MACed. The MAC goes into DE 128 when
+// the message has a secondary bitmap and into DE 64 otherwise, truncated to
+// the field length.
+type MACConfig struct {
+	MACer MACer
+	// Fields selects the fields whose values are MACed, concatenated in the
+	// listed order. Empty MACs the whole message up to the MAC field.
+	Fields []int
+	// FailureCode is the DE 39 of the engine response to a request that
+	// fails verification. Defaults to DefaultMACFailureCode.
+	FailureCode string
+	// Exempt skips messages that carry no MAC. Defaults to network
+	// management (08xx, 18xx) messages.
+	Exempt func(iso ISO8583Object) bool
+}
+
+// StampMAC computes the MAC of iso and sets it in DE 64 or DE 128.
+func StampMAC(iso ISO8583Object, cfg MACConfig) error {
+	field, size := macField(iso)
+	// Placeholder supaya bit MAC ikut di bitmap saat compose
+	iso.SetFieldBytes(field, make([]byte, size))
+
+	raw, err := iso.ComposeBytes()
+	if err != nil {
+		return err
+	}
+	mac, err := cfg.compute(iso, raw, field, size)
+	if err != nil {
+		return err
+	}
+	iso.SetFieldBytes(field, mac)
+	return nil
+}
+
+// VerifyMAC checks the MAC of iso, parsed from raw. It returns ErrMACMissing
+// or ErrMACMismatch when the message does not verify.
+func VerifyMAC(iso ISO8583Object, raw []byte, cfg MACConfig) error {
+	field, size := macField(iso)
+	if !iso.HasField(field) {
+		return ErrMACMissing
+	}
+
+	mac, err := cfg.compute(iso, raw, field, size)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(mac, iso.GetFieldBytes(field)) != 1 {
+		return ErrMACMismatch
+	}
+	return nil
+}
+
+// compute returns the MAC of iso truncated to size bytes. raw is the wire
+// form of iso, whose body ends with the MAC field. Header and trailer are
+// not MACed.
+func (cfg MACConfig) compute(iso ISO8583Object, raw []byte, field, size int) ([]byte, error) {
+	raw = body(iso, raw)
+	var data []byte
+	if len(cfg.Fields) > 0 {
+		for _, f := range cfg.Fields {
+			data = append(data, iso.GetFieldBytes(f)...)
+		}
+	} else {
+		wire := size
+		if spec, ok := fieldConfig(iso, field); ok {
+			wire = len(spec.wireValue(make([]byte, size)))
+		}
+		if len(raw) < wire {
+			return nil, errMessageTruncated
+		}
+		data = raw[:len(raw)-wire]
+	}
+
+	mac, err := cfg.MACer.MAC(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(mac) < size {
+		return nil, errors.New("MAC shorter than the MAC field")
+	}
+	return mac[:size], nil
+}
+
+func (cfg MACConfig) exempt(iso ISO8583Object) bool {
+	if cfg.Exempt != nil {
+		return cfg.Exempt(iso)
+	}
+	return isNetworkMTI(iso.GetMTI())
+}
+
+func (cfg MACConfig) failureCode() string {
+	if cfg.FailureCode != "" {
+		return cfg.FailureCode
+	}
+	return DefaultMACFailureCode
+}
+
+// macField returns the MAC field of iso and its length in bytes.
+func macField(iso ISO8583Object) (field, size int) {
+	field = 64
+	for _, f := range iso.Fields() {
+		if f > 64 {
+			field = 128
+			break
+		}
+	}
+
+	size = 8
+	if spec, ok := fieldConfig(iso, field); ok && spec.MaxLen > 0 {
+		size = spec.MaxLen
+	}
+	return field, size
+}
@@ -0,0 +1,124 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Marshal copies the exported fields of the struct v, tagged with their data
+// element number, into iso:
+//
+//	type Purchase struct {
+//		MTI    string `iso8583:"0"`
+//		PAN    string `iso8583:"2"`
+//		Amount int64  `iso8583:"4"`
+//		Data   string `iso8583:"48,omitempty"`
+//	}
+//
+// Supported field kinds are string, []byte, signed and unsigned integers.
+// With omitempty, zero values are not set on iso.
+func Marshal(iso ISO8583Object, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("iso8583: Marshal expects a struct, got %T", v)
+	}
+
+	return eachTaggedField(rv, func(index int, omitEmpty bool, field reflect.Value) error {
+		if omitEmpty && field.IsZero() {
+			return nil
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			iso.SetField(index, field.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			iso.SetField(index, field.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			iso.SetField(index, field.Uint())
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.Uint8 {
+				return unsupportedKind(index, field)
+			}
+			iso.SetField(index, string(field.Bytes()))
+		default:
+			return unsupportedKind(index, field)
+		}
+		return nil
+	})
+}
+
+// Unmarshal copies the fields of iso into the struct pointed to by v, using
+// the same tags as Marshal. Struct fields whose data element is absent are
+// left untouched.
+func Unmarshal(iso ISO8583Object, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("iso8583: Unmarshal expects a non-nil struct pointer, got %T", v)
+	}
+
+	return eachTaggedField(rv.Elem(), func(index int, _ bool, field reflect.Value) error {
+		if !iso.HasField(index) {
+			return nil
+		}
+		value := iso.GetField(index)
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, field.Type().Bits())
+			if err != nil {
+				return &FieldError{Field: index, Err: err}
+			}
+			field.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(strings.TrimSpace(value), 10, field.Type().Bits())
+			if err != nil {
+				return &FieldError{Field: index, Err: err}
+			}
+			field.SetUint(n)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.Uint8 {
+				return unsupportedKind(index, field)
+			}
+			field.SetBytes([]byte(value))
+		default:
+			return unsupportedKind(index, field)
+		}
+		return nil
+	})
+}
+
+// eachTaggedField calls fn for every exported struct field carrying an
+// iso8583 tag.
+func eachTaggedField(rv reflect.Value, fn func(index int, omitEmpty bool, field reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("iso8583")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		index, err := strconv.Atoi(name)
+		if err != nil || index < 0 {
+			return fmt.Errorf("iso8583: invalid tag %q on field %s", tag, sf.Name)
+		}
+
+		if err := fn(index, opts == "omitempty", rv.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unsupportedKind(index int, field reflect.Value) error {
+	return &FieldError{Field: index, Err: errors.New("unsupported struct field type " + field.Type().String())}
+}
@@ -0,0 +1,92 @@
+package iso8583
+
+import "strings"
+
+// MaskOptions configures Mask. Prefix and Suffix are the PAN digits left
+// visible; PCI DSS allows at most the first 6 and last 4.
+type MaskOptions struct {
+	Prefix int
+	Suffix int
+	// Fields are the fields to mask.
+	Fields []int
+}
+
+// DefaultMaskOptions keeps the first 6 and last 4 PAN digits and masks
+// DefaultSensitiveFields.
+var DefaultMaskOptions = MaskOptions{Prefix: 6, Suffix: 4, Fields: DefaultSensitiveFields}
+
+// Masked implements ISO8583Object. It returns a clone redacted with
+// DefaultMaskOptions, for logs and dashboards; the clone is not meant to be
+// composed and sent.
+func (p *isoObject) Masked() ISO8583Object {
+	return Mask(p, DefaultMaskOptions)
+}
+
+// Mask returns a clone of iso with opts.Fields redacted: DE 2 through
+// MaskPAN, DE 35 through MaskTrack2 and any other field masked entirely.
+func Mask(iso ISO8583Object, opts MaskOptions) ISO8583Object {
+	masked := iso.Clone()
+	for _, field := range opts.Fields {
+		if !masked.HasField(field) {
+			continue
+		}
+		if value := string(masked.GetFieldBytes(field)); value != "" {
+			masked.SetFieldBytes(field, []byte(maskValue(field, value, opts.Prefix, opts.Suffix)))
+		}
+	}
+	return masked
+}
+
+// maskValue redacts the value of a sensitive field: the PAN keeps prefix and
+// suffix digits, track 2 keeps the masked PAN and its separator, anything
+// else is masked entirely.
+func maskValue(field int, value string, prefix, suffix int) string {
+	switch field {
+	case 2:
+		return MaskPAN(value, prefix, suffix)
+	case 35:
+		return MaskTrack2(value, prefix, suffix)
+	default:
+		return strings.Repeat("*", len(value))
+	}
+}
+
+// MaskPAN replaces the digits between the first prefix and last suffix
+// characters with '*'. A PAN too short to keep both is masked entirely.
+func MaskPAN(pan string, prefix, suffix int) string {
+	if prefix < 0 || suffix < 0 || len(pan) <= prefix+suffix {
+		return strings.Repeat("*", len(pan))
+	}
+	return pan[:prefix] + strings.Repeat("*", len(pan)-prefix-suffix) + pan[len(pan)-suffix:]
+}
+
+// TruncatePAN keeps only the first prefix and last suffix digits of pan,
+// e.g. for storing a PAN in truncated form. A PAN too short to keep both
+// truncates to an empty string.
+func TruncatePAN(pan string, prefix, suffix int) string {
+	if prefix < 0 || suffix < 0 || len(pan) <= prefix+suffix {
+		return ""
+	}
+	return pan[:prefix] + pan[len(pan)-suffix:]
+}
+
+// MaskTrack2 masks the PAN of track 2 data through MaskPAN and everything
+// after the field separator ('=' or 'D'), keeping the separator and the
+// sentinels.
+func MaskTrack2(track string, prefix, suffix int) string {
+	start, end := 0, len(track)
+	if strings.HasPrefix(track, ";") {
+		start = 1
+	}
+	if strings.HasSuffix(track, "?") && end > start {
+		end--
+	}
+
+	body := track[start:end]
+	sep := strings.IndexAny(body, "=Dd")
+	if sep < 0 {
+		return track[:start] + strings.Repeat("*", len(body)) + track[end:]
+	}
+	return track[:start] + MaskPAN(body[:sep], prefix, suffix) + body[sep:sep+1] +
+		strings.Repeat("*", len(body)-sep-1) + track[end:]
+}
@@ -0,0 +1,174 @@
+package iso8583
+
+import (
+	"expvar"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics counts the traffic of an engine or client. It is an expvar.Var, so
+// it can be published next to the process metrics and scraped from
+// /debug/vars:
+//
+//	m := iso8583.NewMetrics()
+//	expvar.Publish("iso8583", m)
+//	engine.Metrics = m
+//
+// All methods are safe for concurrent use and no-ops on a nil *Metrics.
+type Metrics struct {
+	MessagesReceived  *expvar.Int
+	MessagesSent      *expvar.Int
+	ParseErrors       *expvar.Int
+	Timeouts          *expvar.Int
+	ActiveConnections *expvar.Int
+	// ResponseCodes counts responses by DE 39 value.
+	ResponseCodes *expvar.Map
+	// Latency is the handler latency for an engine and the round trip time
+	// for a client.
+	Latency *Histogram
+
+	vars expvar.Map
+}
+
+// NewMetrics creates an unpublished set of metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		MessagesReceived:  new(expvar.Int),
+		MessagesSent:      new(expvar.Int),
+		ParseErrors:       new(expvar.Int),
+		Timeouts:          new(expvar.Int),
+		ActiveConnections: new(expvar.Int),
+		ResponseCodes:     new(expvar.Map).Init(),
+		Latency:           NewHistogram(DefaultLatencyBuckets),
+	}
+	m.vars.Set("messages_received", m.MessagesReceived)
+	m.vars.Set("messages_sent", m.MessagesSent)
+	m.vars.Set("parse_errors", m.ParseErrors)
+	m.vars.Set("timeouts", m.Timeouts)
+	m.vars.Set("active_connections", m.ActiveConnections)
+	m.vars.Set("response_codes", m.ResponseCodes)
+	m.vars.Set("latency_seconds", m.Latency)
+	return m
+}
+
+// String implements expvar.Var.
+func (m *Metrics) String() string {
+	return m.vars.String()
+}
+
+func (m *Metrics) received() {
+	if m != nil {
+		m.MessagesReceived.Add(1)
+	}
+}
+
+func (m *Metrics) sent() {
+	if m != nil {
+		m.MessagesSent.Add(1)
+	}
+}
+
+func (m *Metrics) parseError() {
+	if m != nil {
+		m.ParseErrors.Add(1)
+	}
+}
+
+func (m *Metrics) timeout() {
+	if m != nil {
+		m.Timeouts.Add(1)
+	}
+}
+
+func (m *Metrics) connOpened() {
+	if m != nil {
+		m.ActiveConnections.Add(1)
+	}
+}
+
+func (m *Metrics) connClosed() {
+	if m != nil {
+		m.ActiveConnections.Add(-1)
+	}
+}
+
+// responseCode counts the DE 39 of a response, if it has one.
+func (m *Metrics) responseCode(iso ISO8583Object) {
+	if m == nil {
+		return
+	}
+	if rc := iso.GetField(39); rc != "" {
+		m.ResponseCodes.Add(rc, 1)
+	}
+}
+
+func (m *Metrics) observe(start time.Time) {
+	if m != nil {
+		m.Latency.Observe(time.Since(start))
+	}
+}
+
+// DefaultLatencyBuckets are the Histogram upper bounds used by NewMetrics.
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram counts durations into cumulative buckets, Prometheus style. It
+// is an expvar.Var.
+type Histogram struct {
+	bounds []time.Duration
+	counts []atomic.Int64
+	count  atomic.Int64
+	sum    atomic.Int64
+}
+
+// NewHistogram creates a histogram with the given ascending upper bounds; an
+// implicit +Inf bucket catches the rest.
+func NewHistogram(bounds []time.Duration) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]atomic.Int64, len(bounds)),
+	}
+}
+
+// Observe records one duration.
+func (h *Histogram) Observe(d time.Duration) {
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+	for i, bound := range h.bounds {
+		if d <= bound {
+			h.counts[i].Add(1)
+			return
+		}
+	}
+}
+
+// String implements expvar.Var. Bucket counts are cumulative and keyed by
+// their upper bound in seconds.
+func (h *Histogram) String() string {
+	var sb strings.Builder
+	sb.WriteString(`{"buckets":{`)
+	var cumulative int64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		fmt.Fprintf(&sb, "%q:%d,", strconv.FormatFloat(bound.Seconds(), 'f', -1, 64), cumulative)
+	}
+	count := h.count.Load()
+	fmt.Fprintf(&sb, `"+Inf":%d},"count":%d,"sum":%s}`, count, count,
+		strconv.FormatFloat(time.Duration(h.sum.Load()).Seconds(), 'f', -1, 64))
+	return sb.String()
+}
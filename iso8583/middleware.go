@@ -0,0 +1,21 @@
+package iso8583
+
+// Middleware wraps a handler, e.g. for logging, authentication or velocity
+// checks. It may act before and after calling next, or skip next to answer
+// the request itself.
+type Middleware func(next TcpHandler) TcpHandler
+
+// Use appends middleware applied to every routed request, including the
+// default handler. The first middleware added is the outermost one. Call Use
+// before the engine starts serving.
+func (t *TCPIso8583Engine) Use(middleware ...Middleware) {
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// wrap applies the engine middleware to handler.
+func (t *TCPIso8583Engine) wrap(handler TcpHandler) TcpHandler {
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i](handler)
+	}
+	return handler
+}
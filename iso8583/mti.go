@@ -0,0 +1,208 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidMTI is returned for an MTI that is not 4 digits.
+var ErrInvalidMTI = errors.New("MTI must be 4 digits")
+
+// MTI is a message type indicator: four digits for the version, message
+// class, message function and message origin, e.g. 0200 is a 1987
+// financial request from the acquirer.
+//
+//	mti := iso8583.MTI(iso.GetMTI())
+//	if mti.Class() == iso8583.ClassReversal && mti.IsRequest() { ... }
+type MTI string
+
+// ParseMTI returns s as an MTI, failing when it is not 4 digits.
+func ParseMTI(s string) (MTI, error) {
+	if err := ValidateMTI(s); err != nil {
+		return "", err
+	}
+	return MTI(s), nil
+}
+
+// ValidateMTI returns an error wrapping ErrInvalidMTI unless s is 4 digits.
+func ValidateMTI(s string) error {
+	if len(s) != 4 {
+		return fmt.Errorf("%w, got %q", ErrInvalidMTI, s)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return fmt.Errorf("%w, got %q", ErrInvalidMTI, s)
+		}
+	}
+	return nil
+}
+
+// Valid reports whether m is 4 digits. The accessors of an invalid MTI
+// return zero values.
+func (m MTI) Valid() bool {
+	return ValidateMTI(string(m)) == nil
+}
+
+// Version returns Version1987, Version1993 or Version2003, or "" for the
+// other version digits (e.g. 9 for private use).
+func (m MTI) Version() string {
+	return MTIVersion(string(m))
+}
+
+// Class returns the second digit, the overall purpose of the message.
+func (m MTI) Class() MTIClass {
+	return MTIClass(m.digit(1))
+}
+
+// Function returns the third digit, the role of the message in the flow.
+func (m MTI) Function() MTIFunction {
+	return MTIFunction(m.digit(2))
+}
+
+// Origin returns the fourth digit, the source of the message.
+func (m MTI) Origin() MTIOrigin {
+	return MTIOrigin(m.digit(3))
+}
+
+// IsRequest reports whether m expects a response: a request, advice,
+// notification or instruction (even function digit).
+func (m MTI) IsRequest() bool {
+	return m.Valid() && isRequestMTI(string(m))
+}
+
+// IsResponse reports whether m answers a request (odd function digit).
+func (m MTI) IsResponse() bool {
+	return m.Valid() && !isRequestMTI(string(m))
+}
+
+// IsRepeat reports whether m is a repeat of a message sent before (odd
+// origin digit up to 5).
+func (m MTI) IsRepeat() bool {
+	origin := m.Origin()
+	return origin >= OriginAcquirer && origin <= OriginOtherRepeat && (origin-OriginAcquirer)%2 == 1
+}
+
+// ResponseMTI returns the response to m, e.g. 0200 -> 0210 and 0421 ->
+// 0430. Responses and invalid MTIs are returned as is.
+func (m MTI) ResponseMTI() MTI {
+	if !m.Valid() {
+		return m
+	}
+	return MTI(responseMTI(string(m)))
+}
+
+// RepeatMTI returns the repeat form of m, e.g. 0420 -> 0421. Repeats and
+// invalid MTIs are returned as is.
+func (m MTI) RepeatMTI() MTI {
+	if !m.Valid() {
+		return m
+	}
+	return MTI(repeatMTI(string(m)))
+}
+
+// String implements fmt.Stringer.
+func (m MTI) String() string {
+	return string(m)
+}
+
+// digit returns digit i of a valid MTI, 0 otherwise.
+func (m MTI) digit(i int) byte {
+	if !m.Valid() {
+		return 0
+	}
+	return m[i]
+}
+
+// MTIClass is the second MTI digit.
+type MTIClass byte
+
+const (
+	ClassAuthorization     MTIClass = '1'
+	ClassFinancial         MTIClass = '2'
+	ClassFileAction        MTIClass = '3'
+	ClassReversal          MTIClass = '4'
+	ClassReconciliation    MTIClass = '5'
+	ClassAdministrative    MTIClass = '6'
+	ClassFeeCollection     MTIClass = '7'
+	ClassNetworkManagement MTIClass = '8'
+)
+
+var classNames = map[MTIClass]string{
+	ClassAuthorization:     "authorization",
+	ClassFinancial:         "financial",
+	ClassFileAction:        "file action",
+	ClassReversal:          "reversal/chargeback",
+	ClassReconciliation:    "reconciliation",
+	ClassAdministrative:    "administrative",
+	ClassFeeCollection:     "fee collection",
+	ClassNetworkManagement: "network management",
+}
+
+func (c MTIClass) String() string {
+	return digitName(byte(c), classNames[c])
+}
+
+// MTIFunction is the third MTI digit.
+type MTIFunction byte
+
+const (
+	FunctionRequest         MTIFunction = '0'
+	FunctionRequestResponse MTIFunction = '1'
+	FunctionAdvice          MTIFunction = '2'
+	FunctionAdviceResponse  MTIFunction = '3'
+	FunctionNotification    MTIFunction = '4'
+	FunctionNotificationAck MTIFunction = '5'
+	FunctionInstruction     MTIFunction = '6'
+	FunctionInstructionAck  MTIFunction = '7'
+)
+
+var functionNames = map[MTIFunction]string{
+	FunctionRequest:         "request",
+	FunctionRequestResponse: "request response",
+	FunctionAdvice:          "advice",
+	FunctionAdviceResponse:  "advice response",
+	FunctionNotification:    "notification",
+	FunctionNotificationAck: "notification acknowledgement",
+	FunctionInstruction:     "instruction",
+	FunctionInstructionAck:  "instruction acknowledgement",
+}
+
+func (f MTIFunction) String() string {
+	return digitName(byte(f), functionNames[f])
+}
+
+// MTIOrigin is the fourth MTI digit.
+type MTIOrigin byte
+
+const (
+	OriginAcquirer       MTIOrigin = '0'
+	OriginAcquirerRepeat MTIOrigin = '1'
+	OriginIssuer         MTIOrigin = '2'
+	OriginIssuerRepeat   MTIOrigin = '3'
+	OriginOther          MTIOrigin = '4'
+	OriginOtherRepeat    MTIOrigin = '5'
+)
+
+var originNames = map[MTIOrigin]string{
+	OriginAcquirer:       "acquirer",
+	OriginAcquirerRepeat: "acquirer repeat",
+	OriginIssuer:         "issuer",
+	OriginIssuerRepeat:   "issuer repeat",
+	OriginOther:          "other",
+	OriginOtherRepeat:    "other repeat",
+}
+
+func (o MTIOrigin) String() string {
+	return digitName(byte(o), originNames[o])
+}
+
+// digitName returns name, or "reserved (d)" for a digit without one.
+func digitName(digit byte, name string) string {
+	switch {
+	case name != "":
+		return name
+	case digit == 0:
+		return ""
+	}
+	return "reserved (" + string(digit) + ")"
+}
@@ -0,0 +1,99 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownFieldName is returned when a name matches no field Label of the
+// spec, or more than one.
+var ErrUnknownFieldName = errors.New("unknown field name")
+
+// nameAmbiguous marks a name shared by several fields, e.g. "Reserved
+// (national)".
+const nameAmbiguous = -1
+
+// normalizeName makes name lookups case and whitespace insensitive.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// compileNames builds the name to index table from the field Labels. Besides
+// the full Label, a field is found by the Label without its parenthesized
+// suffix and by an upper case abbreviation in it, so DE 2 "Primary account
+// number (PAN)" answers to "primary account number" and "PAN" as well.
+func (pk *Packager) compileNames() {
+	pk.names = make(map[string]int, len(pk.fields)*2)
+	add := func(name string, index int) {
+		key := normalizeName(name)
+		if key == "" {
+			return
+		}
+		if prev, ok := pk.names[key]; ok && prev != index {
+			pk.names[key] = nameAmbiguous
+			return
+		}
+		pk.names[key] = index
+	}
+	for index, f := range pk.fields {
+		add(f.Label, index)
+		open := strings.LastIndexByte(f.Label, '(')
+		if open < 0 || !strings.HasSuffix(f.Label, ")") {
+			continue
+		}
+		add(f.Label[:open], index)
+		if abbr := f.Label[open+1 : len(f.Label)-1]; len(abbr) > 1 && abbr == strings.ToUpper(abbr) {
+			add(abbr, index)
+		}
+	}
+}
+
+// FieldIndex returns the number of the field named name, matched against the
+// spec Labels ignoring case. Names shared by several fields are not found.
+func (pk *Packager) FieldIndex(name string) (int, bool) {
+	index, ok := pk.active().names[normalizeName(name)]
+	if !ok || index == nameAmbiguous {
+		return 0, false
+	}
+	return index, true
+}
+
+// FieldName returns the Label of field index, or "" when the spec does not
+// define it.
+func (pk *Packager) FieldName(index int) string {
+	fs, ok := pk.active().spec(index)
+	if !ok {
+		return ""
+	}
+	return fs.Label
+}
+
+func (p *isoObject) fieldIndex(name string) (int, error) {
+	index, ok := p.packager.FieldIndex(name)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownFieldName, name)
+	}
+	return index, nil
+}
+
+// GetFieldByName implements ISO8583Object. It is GetField with the field
+// given by its Label, see Packager.FieldIndex.
+func (p *isoObject) GetFieldByName(name string) (string, error) {
+	index, err := p.fieldIndex(name)
+	if err != nil {
+		return "", err
+	}
+	return p.GetField(index), nil
+}
+
+// SetFieldByName implements ISO8583Object. It is SetField with the field
+// given by its Label, see Packager.FieldIndex.
+func (p *isoObject) SetFieldByName(name string, val any) error {
+	index, err := p.fieldIndex(name)
+	if err != nil {
+		return err
+	}
+	p.SetField(index, val)
+	return nil
+}
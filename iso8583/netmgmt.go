@@ -0,0 +1,67 @@
+package iso8583
+
+// Default DE 70 network management information codes.
+const (
+	NetworkSignOn  = "001"
+	NetworkSignOff = "002"
+	NetworkEcho    = "301"
+)
+
+// NetworkManagement configures the automatic handling of 08xx (1804 from
+// ISO 8583:1993 on) network management requests. A matching request is
+// answered with the response MTI and DE 39 set to "00" ("000" from 1993 on);
+// a callback may change the response (e.g. set a different DE 39) before
+// the engine sends it. Requests with any other DE 70 value go through the
+// normal handlers.
+type NetworkManagement struct {
+	SignOnCode  string
+	SignOffCode string
+	EchoCode    string
+
+	OnSignOn  func(iso ISO8583Object)
+	OnSignOff func(iso ISO8583Object)
+	OnEcho    func(iso ISO8583Object)
+}
+
+// EnableNetworkManagement turns on automatic 08xx handling. Empty codes fall
+// back to NetworkSignOn, NetworkSignOff and NetworkEcho.
+func (t *TCPIso8583Engine) EnableNetworkManagement(nm NetworkManagement) {
+	if nm.SignOnCode == "" {
+		nm.SignOnCode = NetworkSignOn
+	}
+	if nm.SignOffCode == "" {
+		nm.SignOffCode = NetworkSignOff
+	}
+	if nm.EchoCode == "" {
+		nm.EchoCode = NetworkEcho
+	}
+	t.networkManagement = &nm
+}
+
+// handle answers iso when it is a network management request it knows
+// about. It reports whether iso was handled.
+func (nm *NetworkManagement) handle(iso ISO8583Object) bool {
+	mti := iso.GetMTI()
+	if len(mti) != 4 || !isNetworkMTI(mti) || !isRequestMTI(mti) {
+		return false
+	}
+
+	var callback func(iso ISO8583Object)
+	switch iso.GetField(70) {
+	case nm.SignOnCode:
+		callback = nm.OnSignOn
+	case nm.SignOffCode:
+		callback = nm.OnSignOff
+	case nm.EchoCode:
+		callback = nm.OnEcho
+	default:
+		return false
+	}
+
+	iso.SetMTI(responseMTI(mti))
+	iso.SetField(39, approvalCode(mti))
+	if callback != nil {
+		callback(iso)
+	}
+	return true
+}
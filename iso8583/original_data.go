@@ -0,0 +1,122 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOriginalData is returned for a DE 90 value that is not 42 digits.
+var ErrOriginalData = errors.New("invalid original data elements")
+
+// originalDataLen is the size of DE 90: MTI 4, STAN 6, date and time 10,
+// acquirer ID 11 and forwarder ID 11.
+const originalDataLen = 42
+
+// OriginalData is DE 90, the original data elements a reversal or advice
+// carries to identify the message it refers to. AcquirerID and ForwarderID
+// are kept without their zero fill, like DE 32 and 33.
+type OriginalData struct {
+	// MTI of the original message.
+	MTI string
+	// STAN is DE 11 of the original message.
+	STAN string
+	// TransmissionDateTime is DE 7 of the original message, MMDDhhmmss.
+	TransmissionDateTime string
+	// AcquirerID is DE 32 of the original message.
+	AcquirerID string
+	// ForwarderID is DE 33 of the original message.
+	ForwarderID string
+}
+
+// OriginalDataOf returns the original data elements identifying iso.
+func OriginalDataOf(iso ISO8583Object) OriginalData {
+	return OriginalData{
+		MTI:                  iso.GetMTI(),
+		STAN:                 iso.GetField(11),
+		TransmissionDateTime: iso.GetField(7),
+		AcquirerID:           iso.GetField(32),
+		ForwarderID:          iso.GetField(33),
+	}
+}
+
+// ParseOriginalData parses the 42 digit DE 90 value s.
+func ParseOriginalData(s string) (OriginalData, error) {
+	if len(s) != originalDataLen {
+		return OriginalData{}, fmt.Errorf("%w: length %d, want %d", ErrOriginalData, len(s), originalDataLen)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return OriginalData{}, fmt.Errorf("%w: non-digit %q at position %d", ErrOriginalData, s[i], i)
+		}
+	}
+	return OriginalData{
+		MTI:                  s[:4],
+		STAN:                 s[4:10],
+		TransmissionDateTime: s[10:20],
+		AcquirerID:           trimZeroFill(s[20:31]),
+		ForwarderID:          trimZeroFill(s[31:42]),
+	}, nil
+}
+
+// String returns the 42 digit DE 90 value of d, every part zero filled to
+// its width.
+func (d OriginalData) String() string {
+	return zeroFill(d.MTI, 4) + zeroFill(d.STAN, 6) + zeroFill(d.TransmissionDateTime, 10) +
+		zeroFill(d.AcquirerID, 11) + zeroFill(d.ForwarderID, 11)
+}
+
+// Validate checks every part of d fits its width and holds digits only.
+func (d OriginalData) Validate() error {
+	parts := []struct {
+		name  string
+		value string
+		width int
+	}{
+		{"MTI", d.MTI, 4},
+		{"STAN", d.STAN, 6},
+		{"TransmissionDateTime", d.TransmissionDateTime, 10},
+		{"AcquirerID", d.AcquirerID, 11},
+		{"ForwarderID", d.ForwarderID, 11},
+	}
+	for _, part := range parts {
+		if len(part.value) > part.width {
+			return fmt.Errorf("%w: %s longer than %d digits", ErrOriginalData, part.name, part.width)
+		}
+		for i := 0; i < len(part.value); i++ {
+			if part.value[i] < '0' || part.value[i] > '9' {
+				return fmt.Errorf("%w: %s has non-digit %q", ErrOriginalData, part.name, part.value[i])
+			}
+		}
+	}
+	return nil
+}
+
+// GetOriginalData parses DE 90 of iso.
+func GetOriginalData(iso ISO8583Object) (OriginalData, error) {
+	d, err := ParseOriginalData(iso.GetField(90))
+	if err != nil {
+		return OriginalData{}, &FieldError{Field: 90, Err: err}
+	}
+	return d, nil
+}
+
+// SetOriginalData validates d and sets it as DE 90 of iso.
+func SetOriginalData(iso ISO8583Object, d OriginalData) error {
+	if err := d.Validate(); err != nil {
+		return &FieldError{Field: 90, Err: err}
+	}
+	iso.SetField(90, d.String())
+	return nil
+}
+
+// trimZeroFill drops the zero fill of an ID; an all-zero ID is taken as
+// absent. DE 90 cannot tell leading zeros of the ID itself from the fill, so
+// those are dropped too.
+func trimZeroFill(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' {
+			return s[i:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOriginalDataRoundTrip(t *testing.T) {
+	const de90 = "0200" + "123456" + "1017101010" + "00000123456" + "00000000000"
+	d, err := ParseOriginalData(de90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OriginalData{MTI: "0200", STAN: "123456", TransmissionDateTime: "1017101010", AcquirerID: "123456"}
+	if d != want {
+		t.Errorf("ParseOriginalData = %+v, want %+v", d, want)
+	}
+	if got := d.String(); got != de90 {
+		t.Errorf("String = %q, want %q", got, de90)
+	}
+
+	iso := NewDefaultPackager().NewMessage()
+	if err := SetOriginalData(iso, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetOriginalData(iso); err != nil || got != want {
+		t.Errorf("GetOriginalData = %+v, %v, want %+v", got, err, want)
+	}
+}
+
+func TestOriginalDataRejects(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"0200123456",
+		"0200123456101710101000000123456000000000000",
+		"02001234561017101010000001234560000000000A",
+	} {
+		if _, err := ParseOriginalData(s); !errors.Is(err, ErrOriginalData) {
+			t.Errorf("ParseOriginalData(%q) error = %v, want ErrOriginalData", s, err)
+		}
+	}
+	for _, d := range []OriginalData{
+		{MTI: "02000"},
+		{STAN: "12345A"},
+		{AcquirerID: "123456789012"},
+	} {
+		if err := d.Validate(); !errors.Is(err, ErrOriginalData) {
+			t.Errorf("Validate(%+v) = %v, want ErrOriginalData", d, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultPackager is swapped atomically so Load can run while handlers are
+// creating messages from the previous spec.
+var defaultPackager atomic.Pointer[Packager]
+
+// Packager holds one ISO 8583 spec. Several packagers can live in the same
+// process, e.g. one for the acquirer dialect and one for the issuer dialect.
+//
+// A Packager is read-only once loaded and safe for concurrent use. Set the
+// exported options before the packager is shared and do not change them
+// afterwards; load a new Packager instead.
+//
+// A spec file is a mapping of field number to FieldConfig. Spec-level options
+// such as BitmapEncoding sit next to the field numbers:
+//
+//	BitmapEncoding: binary
+//	0:
+//	  ContentType: "n"
+//	  ...
+//
+// The same layout is accepted as JSON, with the field numbers as keys.
+type Packager struct {
+	// BitmapEncoding is either BitmapHex (default) or BitmapBinary.
+	BitmapEncoding string
+	// TruncateOverLength makes ComposeMessage cut LLVAR/LLLVAR values down to
+	// MaxLen instead of failing.
+	TruncateOverLength bool
+	// Lenient makes ComposeMessage record content type violations as
+	// warnings instead of failing.
+	Lenient bool
+	// Version is the ISO 8583 version of the spec (Version1987, Version1993
+	// or Version2003). When set, Parse and Validate reject an MTI of another
+	// version. Empty accepts any MTI.
+	Version string
+	// ValidatePAN checks DE 2 with card.ValidatePAN (length, digits and Luhn
+	// check digit) in Parse and Validate.
+	ValidatePAN bool
+	// TimeZone is the IANA name of the zone the local date/time fields (all
+	// but DE 7, which is UTC) are read and written in, e.g. "Asia/Jakarta"
+	// for a switch running on WIB. Empty means UTC.
+	TimeZone string
+	// Header and Trailer frame the message, e.g. a TPDU before the MTI and
+	// an ETX with LRC after the last field. See FrameConfig.
+	Header  FrameConfig
+	Trailer FrameConfig
+	// Codecs maps a ContentType to the name of a registered FieldCodec used
+	// for every field of that type without a Codec of its own, e.g.
+	// {"n": "bcd"} for links packing all numeric fields.
+	Codecs map[string]string
+	// Profiles declare the mandatory, conditional and forbidden fields per
+	// MTI and processing code, see ValidateProfile.
+	Profiles []Profile
+
+	fields map[int]FieldConfig
+	// table berisi fields yang sudah diolah untuk parse dan compose
+	table []*fieldSpec
+	// names memetakan Label (huruf kecil) ke nomor field
+	names map[string]int
+	// location adalah TimeZone yang sudah di-load
+	location *time.Location
+
+	// next adalah spec hasil Reload, lihat active
+	next       atomic.Pointer[Packager]
+	generation atomic.Uint64
+}
+
+// LoadSpec reads a packager spec from specFile. Files ending in .json are
+// decoded as JSON, anything else as YAML.
+func LoadSpec(specFile string) (*Packager, error) {
+	data, er := os.ReadFile(specFile)
+	if er != nil {
+		return nil, er
+	}
+
+	if strings.EqualFold(filepath.Ext(specFile), ".json") {
+		return decodeSpec(data, specFormatJSON)
+	}
+	return decodeSpec(data, specFormatYAML)
+}
+
+// LoadSpecJSON reads a JSON packager spec from specFile regardless of its
+// extension.
+func LoadSpecJSON(specFile string) (*Packager, error) {
+	data, er := os.ReadFile(specFile)
+	if er != nil {
+		return nil, er
+	}
+
+	return decodeSpec(data, specFormatJSON)
+}
+
+// LoadSpecFromReader reads a packager spec from r, e.g. an embedded asset or
+// a remote config service. JSON is detected by a leading '{'.
+func LoadSpecFromReader(r io.Reader) (*Packager, error) {
+	data, er := io.ReadAll(r)
+	if er != nil {
+		return nil, er
+	}
+
+	return LoadSpecFromBytes(data)
+}
+
+// LoadSpecFromBytes decodes a packager spec held in memory. JSON is detected
+// by a leading '{'.
+func LoadSpecFromBytes(data []byte) (*Packager, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return decodeSpec(data, specFormatJSON)
+	}
+	return decodeSpec(data, specFormatYAML)
+}
+
+const (
+	specFormatYAML = iota
+	specFormatJSON
+)
+
+func decodeSpec(data []byte, format int) (*Packager, error) {
+	packager := &Packager{}
+
+	var er error
+	if format == specFormatJSON {
+		er = json.Unmarshal(data, packager)
+	} else {
+		er = yaml.Unmarshal(data, packager)
+	}
+	if er != nil {
+		return nil, er
+	}
+
+	return packager, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (pk *Packager) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: spec must be a mapping", node.Line)
+	}
+
+	pk.fields = make(map[int]FieldConfig)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if err := pk.decodeEntry(key.Value, value.Decode); err != nil {
+			return fmt.Errorf("line %d: %w", key.Line, err)
+		}
+	}
+
+	return pk.finalize()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (pk *Packager) UnmarshalJSON(data []byte) error {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	pk.fields = make(map[int]FieldConfig)
+	for key, value := range entries {
+		decode := func(v any) error {
+			return json.Unmarshal(value, v)
+		}
+		if err := pk.decodeEntry(key, decode); err != nil {
+			return err
+		}
+	}
+
+	return pk.finalize()
+}
+
+// decodeEntry handles one top-level spec entry, either a field number or a
+// spec-level option.
+func (pk *Packager) decodeEntry(key string, decode func(v any) error) error {
+	if index, err := strconv.Atoi(key); err == nil {
+		var field FieldConfig
+		if err := decode(&field); err != nil {
+			return fmt.Errorf("field %d: %w", index, err)
+		}
+		if field.Encoding == "" {
+			field.Encoding = EncodingASCII
+		}
+		if field.SubFieldFormat == "" {
+			field.SubFieldFormat = SubFieldPositional
+		}
+		pk.fields[index] = field
+		return nil
+	}
+
+	switch key {
+	case "BitmapEncoding":
+		return decode(&pk.BitmapEncoding)
+	case "TruncateOverLength":
+		return decode(&pk.TruncateOverLength)
+	case "Lenient":
+		return decode(&pk.Lenient)
+	case "Version":
+		return decode(&pk.Version)
+	case "TimeZone":
+		return decode(&pk.TimeZone)
+	case "ValidatePAN":
+		return decode(&pk.ValidatePAN)
+	case "Header":
+		return decode(&pk.Header)
+	case "Trailer":
+		return decode(&pk.Trailer)
+	case "Codecs":
+		return decode(&pk.Codecs)
+	case "Profiles":
+		return decode(&pk.Profiles)
+	default:
+		return fmt.Errorf("unknown spec option %q", key)
+	}
+}
+
+// finalize applies defaults once every entry has been decoded and checks the
+// spec as a whole.
+func (pk *Packager) finalize() error {
+	if pk.BitmapEncoding == "" {
+		pk.BitmapEncoding = BitmapHex
+	}
+
+	if err := pk.validate(); err != nil {
+		return err
+	}
+	if err := pk.resolveCodecs(); err != nil {
+		return err
+	}
+	pk.compile()
+	pk.compileNames()
+	return nil
+}
+
+// NewMessage creates an empty message bound to this packager, or to the
+// spec it was last reloaded with, see Reload.
+func (pk *Packager) NewMessage() ISO8583Object {
+	return &isoObject{
+		isoElement: make(map[int]string, 0),
+		packager:   pk.active(),
+	}
+}
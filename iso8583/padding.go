@@ -0,0 +1,40 @@
+package iso8583
+
+const (
+	PadLeft  = "left"
+	PadRight = "right"
+	// PadNone rejects fixed values shorter than MaxLen instead of filling
+	// them.
+	PadNone = "none"
+)
+
+// padding returns the pad side and character for the field. Unset values
+// fall back to zeros on the left for "n", 0x00 on the right for "b" and
+// spaces on the right for everything else.
+func (f FieldConfig) padding() (pad string, padChar string) {
+	pad, padChar = f.Pad, f.PadChar
+	switch f.ContentType {
+	case "n":
+		if pad == "" {
+			pad = PadLeft
+		}
+		if padChar == "" {
+			padChar = "0"
+		}
+	case "b":
+		if pad == "" {
+			pad = PadRight
+		}
+		if padChar == "" {
+			padChar = "\x00"
+		}
+	default:
+		if pad == "" {
+			pad = PadRight
+		}
+		if padChar == "" {
+			padChar = " "
+		}
+	}
+	return pad, padChar
+}
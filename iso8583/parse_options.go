@@ -0,0 +1,57 @@
+package iso8583
+
+// ParseMode selects how ParseWithOptions reacts to malformed input.
+type ParseMode int
+
+const (
+	// ParseDefault fails on anything that prevents reading the message
+	// (unknown bits, bad or truncated lengths) and ignores the rest; this is
+	// what Parse and ParseBytes do.
+	ParseDefault ParseMode = iota
+	// ParseStrict additionally rejects variable lengths above MaxLen,
+	// content type violations, trailing bytes and an MTI that is not 4
+	// digits.
+	ParseStrict
+	// ParseLenient never fails after the MTI and bitmap. Problems are
+	// recorded per field in Warnings, and parsing stops at the first one
+	// that makes the rest of the message unreadable, keeping the fields read
+	// so far. Useful for log replay and forensic tooling.
+	ParseLenient
+)
+
+// ParseOptions tunes ParseWithOptions.
+type ParseOptions struct {
+	Mode ParseMode
+
+	// trace dipanggil untuk setiap field yang terbaca, dipakai DumpHex
+	trace func(field, start, end int)
+}
+
+// mark reports the wire bytes [start, end) of the message body as field.
+func (opts ParseOptions) mark(field, start, end int) {
+	if opts.trace != nil {
+		opts.trace(field, start, end)
+	}
+}
+
+// parseFailure handles a problem after which the rest of the message cannot
+// be read.
+func (p *isoObject) parseFailure(opts ParseOptions, perr *ParseError) error {
+	if opts.Mode == ParseLenient {
+		p.warnings = append(p.warnings, perr)
+		return nil
+	}
+	return perr
+}
+
+// parseViolation handles a problem parsing can continue past. It returns a
+// non-nil error only when parsing must stop.
+func (p *isoObject) parseViolation(opts ParseOptions, perr *ParseError) error {
+	switch opts.Mode {
+	case ParseStrict:
+		return perr
+	case ParseLenient:
+		p.warnings = append(p.warnings, perr)
+	}
+	return nil
+}
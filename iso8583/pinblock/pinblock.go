@@ -0,0 +1,288 @@
+// Package pinblock builds and parses ISO 9564-1 PIN blocks for DE 52.
+//
+// Formats 0, 1 and 3 are 8 byte blocks enciphered with DES/3DES; format 4 is
+// a 16 byte block enciphered with AES. Encode and Decode work on clear
+// blocks, Encrypt and Decrypt add the encipherment and Translate re-enciphers
+// a block under another key and format, as a switch does between zones.
+package pinblock
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format is an ISO 9564-1 PIN block format.
+type Format int
+
+const (
+	Format0 Format = 0 // PIN XOR PAN, 'F' fill
+	Format1 Format = 1 // PIN with random fill, no PAN
+	Format3 Format = 3 // PIN XOR PAN, random 'A'-'F' fill
+	Format4 Format = 4 // AES, PIN and PAN fields enciphered separately
+)
+
+var (
+	ErrFormat = errors.New("pinblock: unsupported format")
+	ErrPIN    = errors.New("pinblock: PIN must be 4 to 12 digits")
+	ErrPAN    = errors.New("pinblock: invalid PAN")
+	ErrBlock  = errors.New("pinblock: malformed PIN block")
+)
+
+// Encode builds the clear PIN block of pin for format 0, 1 or 3. pan is
+// ignored by format 1.
+func Encode(format Format, pin, pan string) ([]byte, error) {
+	if err := checkPIN(pin); err != nil {
+		return nil, err
+	}
+
+	var fill func(n int) (string, error)
+	switch format {
+	case Format0:
+		fill = func(n int) (string, error) { return strings.Repeat("F", n), nil }
+	case Format1:
+		fill = func(n int) (string, error) { return randomNibbles(n, 0x0, 0xF) }
+	case Format3:
+		fill = func(n int) (string, error) { return randomNibbles(n, 0xA, 0xF) }
+	default:
+		return nil, ErrFormat
+	}
+
+	padding, err := fill(14 - len(pin))
+	if err != nil {
+		return nil, err
+	}
+	block, err := hex.DecodeString(fmt.Sprintf("%d%X%s%s", format, len(pin), pin, padding))
+	if err != nil {
+		return nil, err
+	}
+	if format == Format1 {
+		return block, nil
+	}
+
+	panField, err := panBlock(pan)
+	if err != nil {
+		return nil, err
+	}
+	xorBytes(block, panField)
+	return block, nil
+}
+
+// Decode extracts the PIN from a clear format 0, 1 or 3 block.
+func Decode(format Format, block []byte, pan string) (string, error) {
+	if len(block) != 8 {
+		return "", ErrBlock
+	}
+	clear := append([]byte(nil), block...)
+	switch format {
+	case Format0, Format3:
+		panField, err := panBlock(pan)
+		if err != nil {
+			return "", err
+		}
+		xorBytes(clear, panField)
+	case Format1:
+	default:
+		return "", ErrFormat
+	}
+	return readPINField(format, strings.ToUpper(hex.EncodeToString(clear)))
+}
+
+// Encrypt builds the PIN block of pin enciphered under key: a DES or 3DES
+// key (8, 16 or 24 bytes) for formats 0, 1 and 3, an AES key for format 4.
+func Encrypt(format Format, pin, pan string, key []byte) ([]byte, error) {
+	if format == Format4 {
+		return encryptFormat4(pin, pan, key)
+	}
+
+	block, err := Encode(format, pin, pan)
+	if err != nil {
+		return nil, err
+	}
+	c, err := desCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	c.Encrypt(block, block)
+	return block, nil
+}
+
+// Decrypt deciphers block under key and extracts the PIN.
+func Decrypt(format Format, block []byte, pan string, key []byte) (string, error) {
+	if format == Format4 {
+		return decryptFormat4(block, pan, key)
+	}
+
+	if len(block) != 8 {
+		return "", ErrBlock
+	}
+	c, err := desCipher(key)
+	if err != nil {
+		return "", err
+	}
+	clear := make([]byte, 8)
+	c.Decrypt(clear, block)
+	return Decode(format, clear, pan)
+}
+
+// Translate re-enciphers block from one key and format to another without
+// handing the PIN to the caller.
+func Translate(block []byte, pan string, from Format, fromKey []byte, to Format, toKey []byte) ([]byte, error) {
+	pin, err := Decrypt(from, block, pan, fromKey)
+	if err != nil {
+		return nil, err
+	}
+	return Encrypt(to, pin, pan, toKey)
+}
+
+func encryptFormat4(pin, pan string, key []byte) ([]byte, error) {
+	if err := checkPIN(pin); err != nil {
+		return nil, err
+	}
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	random, err := randomNibbles(16, 0x0, 0xF)
+	if err != nil {
+		return nil, err
+	}
+	pinField, err := hex.DecodeString(fmt.Sprintf("4%X%s%s%s", len(pin), pin, strings.Repeat("A", 14-len(pin)), random))
+	if err != nil {
+		return nil, err
+	}
+	panField, err := panBlock4(pan)
+	if err != nil {
+		return nil, err
+	}
+
+	block := make([]byte, aes.BlockSize)
+	c.Encrypt(block, pinField)
+	xorBytes(block, panField)
+	c.Encrypt(block, block)
+	return block, nil
+}
+
+func decryptFormat4(block []byte, pan string, key []byte) (string, error) {
+	if len(block) != aes.BlockSize {
+		return "", ErrBlock
+	}
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	panField, err := panBlock4(pan)
+	if err != nil {
+		return "", err
+	}
+
+	clear := make([]byte, aes.BlockSize)
+	c.Decrypt(clear, block)
+	xorBytes(clear, panField)
+	c.Decrypt(clear, clear)
+	return readPINField(Format4, strings.ToUpper(hex.EncodeToString(clear)))
+}
+
+// readPINField extracts the PIN from the hex form of a clear PIN field.
+func readPINField(format Format, field string) (string, error) {
+	if field[0] != byte('0'+format) {
+		return "", ErrBlock
+	}
+	n := strings.IndexByte("0123456789ABCDEF", field[1])
+	if n < 4 || n > 12 {
+		return "", ErrBlock
+	}
+	pin := field[2 : 2+n]
+	if strings.Trim(pin, "0123456789") != "" {
+		return "", ErrBlock
+	}
+
+	fill := field[2+n : 16]
+	switch format {
+	case Format0:
+		if strings.Trim(fill, "F") != "" {
+			return "", ErrBlock
+		}
+	case Format3:
+		if strings.Trim(fill, "ABCDEF") != "" {
+			return "", ErrBlock
+		}
+	case Format4:
+		if strings.Trim(fill, "A") != "" {
+			return "", ErrBlock
+		}
+	}
+	return pin, nil
+}
+
+// panBlock is the format 0/3 PAN field: 4 zero digits followed by the 12
+// rightmost PAN digits excluding the check digit.
+func panBlock(pan string) ([]byte, error) {
+	if len(pan) < 13 || strings.Trim(pan, "0123456789") != "" {
+		return nil, ErrPAN
+	}
+	digits := pan[len(pan)-13 : len(pan)-1]
+	return hex.DecodeString("0000" + digits)
+}
+
+// panBlock4 is the format 4 PAN field: the PAN length minus 12, the PAN
+// (left padded with zeros to 12 digits) and zero fill.
+func panBlock4(pan string) ([]byte, error) {
+	if len(pan) == 0 || len(pan) > 19 || strings.Trim(pan, "0123456789") != "" {
+		return nil, ErrPAN
+	}
+	m := 0
+	if len(pan) > 12 {
+		m = len(pan) - 12
+	} else {
+		pan = strings.Repeat("0", 12-len(pan)) + pan
+	}
+	field := fmt.Sprintf("%d%s", m, pan)
+	field += strings.Repeat("0", 32-len(field))
+	return hex.DecodeString(field)
+}
+
+func checkPIN(pin string) error {
+	if len(pin) < 4 || len(pin) > 12 || strings.Trim(pin, "0123456789") != "" {
+		return ErrPIN
+	}
+	return nil
+}
+
+// randomNibbles returns n random hex digits between lo and hi.
+func randomNibbles(n int, lo, hi byte) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	span := hi - lo + 1
+	var sb strings.Builder
+	for _, v := range b {
+		sb.WriteByte("0123456789ABCDEF"[lo+v%span])
+	}
+	return sb.String(), nil
+}
+
+func desCipher(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 8:
+		return des.NewCipher(key)
+	case 16:
+		return des.NewTripleDESCipher(append(append([]byte(nil), key...), key[:8]...))
+	case 24:
+		return des.NewTripleDESCipher(key)
+	default:
+		return nil, fmt.Errorf("pinblock: invalid key length %d", len(key))
+	}
+}
+
+func xorBytes(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,157 @@
+package pinblock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	testPAN = "43219876543210987"
+	testPIN = "1234"
+)
+
+var (
+	testKey    = unhex("0123456789ABCDEFFEDCBA9876543210")
+	testAESKey = unhex("00112233445566778899AABBCCDDEEFF")
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestEncodeFormat0(t *testing.T) {
+	block, err := Encode(Format0, testPIN, testPAN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := unhex("0412AC89ABCDEF67"); !bytes.Equal(block, want) {
+		t.Fatalf("block = %X, want %X", block, want)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		format Format
+		block  string
+	}{
+		{Format0, "0412AC89ABCDEF67"},
+		{Format1, "1412345A3C9E0B71"},
+		{Format3, "3412ACDD99DDBB55"},
+	}
+	for _, tt := range tests {
+		pin, err := Decode(tt.format, unhex(tt.block), testPAN)
+		if err != nil {
+			t.Errorf("format %d: %v", tt.format, err)
+			continue
+		}
+		if pin != testPIN {
+			t.Errorf("format %d: PIN = %q, want %q", tt.format, pin, testPIN)
+		}
+	}
+}
+
+func TestEncodeRandomFill(t *testing.T) {
+	for _, format := range []Format{Format1, Format3} {
+		block, err := Encode(format, testPIN, testPAN)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pin, err := Decode(format, block, testPAN)
+		if err != nil || pin != testPIN {
+			t.Fatalf("format %d: Decode = %q, %v", format, pin, err)
+		}
+	}
+
+	// Format 1 tidak memakai PAN, blok clear diawali kontrol, panjang dan PIN
+	block, _ := Encode(Format1, testPIN, "")
+	if got := strings.ToUpper(hex.EncodeToString(block)); !strings.HasPrefix(got, "141234") {
+		t.Fatalf("format 1 block = %s", got)
+	}
+}
+
+func TestEncryptFormat0(t *testing.T) {
+	block, err := Encrypt(Format0, testPIN, testPAN, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := unhex("C967C8198151A458"); !bytes.Equal(block, want) {
+		t.Fatalf("block = %X, want %X", block, want)
+	}
+	pin, err := Decrypt(Format0, block, testPAN, testKey)
+	if err != nil || pin != testPIN {
+		t.Fatalf("Decrypt = %q, %v", pin, err)
+	}
+}
+
+func TestFormat4(t *testing.T) {
+	const pan = "432198765432109870"
+	pin, err := Decrypt(Format4, unhex("46FAB89E6A2B47336B4420F7B465419D"), pan, testAESKey)
+	if err != nil || pin != testPIN {
+		t.Fatalf("Decrypt = %q, %v", pin, err)
+	}
+
+	block, err := Encrypt(Format4, "123456789012", pan, testAESKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block) != 16 {
+		t.Fatalf("block length %d, want 16", len(block))
+	}
+	pin, err = Decrypt(Format4, block, pan, testAESKey)
+	if err != nil || pin != "123456789012" {
+		t.Fatalf("Decrypt = %q, %v", pin, err)
+	}
+	if _, err := Decrypt(Format4, block, "432198765432109871", testAESKey); !errors.Is(err, ErrBlock) {
+		t.Fatalf("Decrypt with another PAN: got %v, want ErrBlock", err)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	toKey := unhex("FEDCBA98765432100123456789ABCDEF")
+	block, err := Encrypt(Format0, testPIN, testPAN, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated, err := Translate(block, testPAN, Format0, testKey, Format3, toKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pin, err := Decrypt(Format3, translated, testPAN, toKey)
+	if err != nil || pin != testPIN {
+		t.Fatalf("Decrypt = %q, %v", pin, err)
+	}
+	if _, err := Decrypt(Format0, translated, testPAN, toKey); err == nil {
+		t.Fatal("format 3 block decoded as format 0")
+	}
+}
+
+func TestRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		fn   func() error
+	}{
+		{"short PAN", ErrPAN, func() error { _, err := Encode(Format0, testPIN, "432198765432"); return err }},
+		{"non-digit PAN", ErrPAN, func() error { _, err := Encode(Format3, testPIN, "4321987654321098X"); return err }},
+		{"3 digit PIN", ErrPIN, func() error { _, err := Encode(Format0, "123", testPAN); return err }},
+		{"13 digit PIN", ErrPIN, func() error { _, err := Encode(Format0, "1234567890123", testPAN); return err }},
+		{"13 digit PIN format 4", ErrPIN, func() error { _, err := Encrypt(Format4, "1234567890123", testPAN, testAESKey); return err }},
+		{"unknown format", ErrFormat, func() error { _, err := Encode(Format(2), testPIN, testPAN); return err }},
+		{"corrupted fill", ErrBlock, func() error { _, err := Decode(Format0, unhex("0412AC89ABCDEF68"), testPAN); return err }},
+		{"corrupted control", ErrBlock, func() error { _, err := Decode(Format0, unhex("1412AC89ABCDEF67"), testPAN); return err }},
+		{"short block", ErrBlock, func() error { _, err := Decode(Format0, unhex("0412AC89"), testPAN); return err }},
+		{"format 3 fill in format 0", ErrBlock, func() error { _, err := Decode(Format0, unhex("3412ACDD99DDBB55"), testPAN); return err }},
+	}
+	for _, tt := range tests {
+		if err := tt.fn(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
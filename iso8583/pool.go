@@ -0,0 +1,221 @@
+package iso8583
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoConnection is returned by Pool.Send when no pooled connection is up.
+var ErrNoConnection = errors.New("iso8583 pool has no usable connection")
+
+// Pool spreads requests over several persistent connections to the same
+// host. Dropped connections are redialed and idle ones are health checked
+// with 0800 echo messages every HealthCheckInterval.
+type Pool struct {
+	Address      string
+	Size         int
+	Timeout      time.Duration
+	KeyFields    []int
+	LengthHeader LengthHeader
+	MaxFrameSize int
+	TLSConfig    *tls.Config
+	// Timeouts overrides Timeout per MTI prefix, see ISOClient.Timeouts.
+	Timeouts map[string]time.Duration
+	// Packager used for responses and the default echo message. When nil
+	// the spec loaded by Load is used.
+	Packager            *Packager
+	HealthCheckInterval time.Duration
+	// EchoMessage builds the health check request. The default is an 0800
+	// with DE 70 set to NetworkEcho.
+	EchoMessage func() (ISO8583Object, error)
+	// STAN issues DE 11 of the default echo. Share the generator used for
+	// application requests so an echo never takes the STAN of one in
+	// flight; when nil the pool keeps its own counter.
+	STAN *STANGenerator
+	// Metrics, when set, is shared by every connection of the pool.
+	Metrics *Metrics
+	// WireLog and Logger are passed to every connection of the pool.
+	WireLog *WireLog
+	Logger  *slog.Logger
+
+	clients  []*ISOClient
+	next     atomic.Uint64
+	echoSTAN *STANGenerator
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewPool creates a pool of size connections to address. timeout is the
+// response timeout in seconds, keyFields default to DE 11.
+func NewPool(address string, size int, timeout int, keyFields ...int) *Pool {
+	if len(keyFields) == 0 {
+		keyFields = []int{11}
+	}
+	return &Pool{
+		Address:             address,
+		Size:                size,
+		Timeout:             time.Duration(timeout) * time.Second,
+		KeyFields:           keyFields,
+		HealthCheckInterval: 30 * time.Second,
+	}
+}
+
+// Connect dials every connection and starts the health checker. It fails
+// only when no connection at all could be established; the others are
+// retried by the health checker.
+func (p *Pool) Connect() error {
+	if p.Size <= 0 {
+		return fmt.Errorf("invalid pool size %d", p.Size)
+	}
+
+	p.stop = make(chan struct{})
+	p.clients = make([]*ISOClient, p.Size)
+	var lastErr error
+	connected := 0
+	for i := range p.clients {
+		p.clients[i] = &ISOClient{
+			Address:      p.Address,
+			Timeout:      p.Timeout,
+			Timeouts:     p.Timeouts,
+			KeyFields:    p.KeyFields,
+			LengthHeader: p.LengthHeader,
+			MaxFrameSize: p.MaxFrameSize,
+			TLSConfig:    p.TLSConfig,
+			Packager:     p.Packager,
+			Metrics:      p.Metrics,
+			WireLog:      p.WireLog,
+			Logger:       p.Logger,
+		}
+		if err := p.clients[i].Connect(); err != nil {
+			lastErr = err
+			continue
+		}
+		connected++
+	}
+	if connected == 0 {
+		return lastErr
+	}
+
+	go p.healthCheck()
+	return nil
+}
+
+// Send sends iso on the next usable connection, round robin, waiting for
+// the response as long as Timeouts or Timeout allow for its MTI.
+func (p *Pool) Send(iso ISO8583Object) (ISO8583Object, error) {
+	client := p.pick()
+	if client == nil {
+		return nil, ErrNoConnection
+	}
+	return client.Send(iso)
+}
+
+// SendWithTimeout is like Send with an explicit response timeout.
+func (p *Pool) SendWithTimeout(iso ISO8583Object, timeout time.Duration) (ISO8583Object, error) {
+	client := p.pick()
+	if client == nil {
+		return nil, ErrNoConnection
+	}
+	return client.SendWithTimeout(iso, timeout)
+}
+
+// pick returns the next usable connection, or nil when none is up.
+func (p *Pool) pick() *ISOClient {
+	for range p.clients {
+		client := p.clients[p.next.Add(1)%uint64(len(p.clients))]
+		if client.Connected() {
+			return client
+		}
+	}
+	return nil
+}
+
+// Close stops the health checker and closes every connection.
+func (p *Pool) Close() error {
+	p.once.Do(func() {
+		if p.stop != nil {
+			close(p.stop)
+		}
+	})
+
+	var firstErr error
+	for _, client := range p.clients {
+		if err := client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (p *Pool) healthCheck() {
+	ticker := time.NewTicker(p.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for _, client := range p.clients {
+			if !client.Connected() {
+				_ = client.Connect()
+				continue
+			}
+			echo, err := p.echoMessage()
+			if err != nil {
+				continue
+			}
+			switch _, err := client.Send(echo); {
+			case err == nil:
+			case isLinkError(err):
+				// Koneksi tidak merespon echo, tutup dan dial ulang
+				client.log().Warn("echo failed, redialing", "address", p.Address, "err", err)
+				_ = client.Close()
+				_ = client.Connect()
+			default:
+				// Link masih hidup, jangan gagalkan request yang pending
+				client.log().Warn("echo failed", "address", p.Address, "err", err)
+			}
+		}
+	}
+}
+
+func (p *Pool) echoMessage() (ISO8583Object, error) {
+	if p.EchoMessage != nil {
+		return p.EchoMessage()
+	}
+
+	var (
+		iso ISO8583Object
+		err error
+	)
+	if p.Packager != nil {
+		iso = p.Packager.NewMessage()
+	} else if iso, err = NewISO8583(); err != nil {
+		return nil, err
+	}
+	stan := p.STAN
+	if stan == nil {
+		// Hanya dipanggil dari goroutine healthCheck
+		if p.echoSTAN == nil {
+			p.echoSTAN, _ = NewSTANGenerator(nil)
+		}
+		stan = p.echoSTAN
+	}
+	trace, err := stan.Next()
+	if err != nil {
+		return nil, err
+	}
+	iso.SetMTI("0800")
+	iso.SetField(7, time.Now().UTC().Format("0102150405"))
+	iso.SetField(11, trace)
+	iso.SetField(70, NetworkEcho)
+	return iso, nil
+}
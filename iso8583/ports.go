@@ -0,0 +1,122 @@
+package iso8583
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// PortConfig binds a listening port to its own spec and routes, so one
+// engine can serve e.g. a POS dialect on 7001 and an ATM dialect on 7002.
+// Everything else (middleware, limits, timeouts, logging) is shared by the
+// engine.
+type PortConfig struct {
+	// Port to listen on, as passed to Run.
+	Port string
+	// Listener, when set, is served instead of listening on Port, e.g. a
+	// unix socket or a socket passed in by systemd.
+	Listener net.Listener
+	// Packager parses the requests of the port. Defaults to the spec loaded
+	// by Load.
+	Packager *Packager
+	// Router routes the requests of the port. Defaults to the engine
+	// router.
+	Router *Router
+	// TLSConfig, when set, serves TLS on the port.
+	TLSConfig *tls.Config
+}
+
+// newMessage creates an empty message bound to the port spec.
+func (p *PortConfig) newMessage() (ISO8583Object, error) {
+	if p != nil && p.Packager != nil {
+		return p.Packager.NewMessage(), nil
+	}
+	return NewISO8583()
+}
+
+// router returns the port router, or the engine router when the port has
+// none.
+func (t *TCPIso8583Engine) portRouter(p *PortConfig) *Router {
+	if p != nil && p.Router != nil {
+		return p.Router
+	}
+	return t.router
+}
+
+// RunPorts listens on every port and serves them until Stop or Shutdown.
+// When a port cannot be opened the ones already opened are closed again and
+// the error is returned.
+func (t *TCPIso8583Engine) RunPorts(ports ...PortConfig) error {
+	listeners := make([]net.Listener, 0, len(ports))
+	for i := range ports {
+		listener, err := t.openPort(&ports[i])
+		if err != nil {
+			for _, l := range listeners {
+				t.trackListener(l, false)
+				_ = l.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(listeners))
+	for i, listener := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = t.acceptConnection(listener, &ports[i])
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrEngineClosed) {
+			return err
+		}
+	}
+	return ErrEngineClosed
+}
+
+// RunPortInBackground starts serving port and returns once it listens.
+func (t *TCPIso8583Engine) RunPortInBackground(port PortConfig) error {
+	listener, err := t.openPort(&port)
+	if err != nil {
+		return err
+	}
+	go func() {
+		_ = t.acceptConnection(listener, &port)
+	}()
+	return nil
+}
+
+// Serve accepts connections on listener until Stop or Shutdown, which
+// close it. It lets the engine run on any net.Listener, e.g. a unix socket:
+//
+//	listener, err := net.Listen("unix", "/run/switch.sock")
+//	...
+//	err = engine.Serve(listener)
+func (t *TCPIso8583Engine) Serve(listener net.Listener) error {
+	return t.RunPorts(PortConfig{Listener: listener})
+}
+
+// openPort opens the listener of port and registers it for Shutdown.
+func (t *TCPIso8583Engine) openPort(port *PortConfig) (net.Listener, error) {
+	listener := port.Listener
+	if listener == nil {
+		var err error
+		if listener, err = net.Listen("tcp", fmt.Sprint(":", port.Port)); err != nil {
+			return nil, err
+		}
+	}
+	if port.TLSConfig != nil {
+		listener = tls.NewListener(listener, t.serverTLSConfig(port.TLSConfig))
+	}
+	if !t.trackListener(listener, true) {
+		_ = listener.Close()
+		return nil, ErrEngineClosed
+	}
+	return listener, nil
+}
@@ -0,0 +1,27 @@
+package iso8583
+
+import "testing"
+
+func TestPrettyPrintKeepsWarnings(t *testing.T) {
+	iso := exchangeRequest("0200")
+	// Check digit salah: parse lenient mencatatnya sebagai warning
+	iso.SetField(2, "4111111111111112")
+	raw, err := iso.ComposeBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packager := NewDefaultPackager()
+	packager.ValidatePAN = true
+	parsed := packager.NewMessage()
+	if err := parsed.ParseWithOptions(raw, ParseOptions{Mode: ParseLenient}); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Warnings()) == 0 {
+		t.Fatal("no warning for an invalid PAN")
+	}
+	parsed.PrettyPrintWithOptions(PrintOptions{Mask: true, HexDump: true})
+	if len(parsed.Warnings()) == 0 {
+		t.Error("PrettyPrintWithOptions cleared the parse warnings")
+	}
+}
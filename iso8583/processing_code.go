@@ -0,0 +1,83 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrProcessingCode is returned for a DE 3 value that is not 6 digits.
+var ErrProcessingCode = errors.New("invalid processing code")
+
+// Common transaction types, the first two digits of DE 3. Being a prefix of
+// DE 3 they also work as Route.ProcessingCode.
+const (
+	TxPurchase         = "00"
+	TxWithdrawal       = "01"
+	TxPurchaseCashback = "09"
+	TxRefund           = "20"
+	TxDeposit          = "21"
+	TxBalanceInquiry   = "31"
+	TxTransfer         = "40"
+	TxPayment          = "50"
+)
+
+// Account types, the from and to account digits of DE 3.
+const (
+	AccountDefault   = "00"
+	AccountSavings   = "10"
+	AccountChecking  = "20"
+	AccountCredit    = "30"
+	AccountUniversal = "40"
+)
+
+// ProcessingCode is DE 3: the transaction type followed by the from and to
+// account types, 2 digits each.
+type ProcessingCode struct {
+	TransactionType string
+	FromAccount     string
+	ToAccount       string
+}
+
+// ParseProcessingCode parses the 6 digit DE 3 value s.
+func ParseProcessingCode(s string) (ProcessingCode, error) {
+	if len(s) != 6 {
+		return ProcessingCode{}, fmt.Errorf("%w: length %d, want 6", ErrProcessingCode, len(s))
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return ProcessingCode{}, fmt.Errorf("%w: non-digit %q at position %d", ErrProcessingCode, s[i], i)
+		}
+	}
+	return ProcessingCode{TransactionType: s[:2], FromAccount: s[2:4], ToAccount: s[4:]}, nil
+}
+
+// String returns the 6 digit DE 3 value of pc. Empty parts are written as
+// "00", e.g. ProcessingCode{TransactionType: TxBalanceInquiry} is "310000".
+func (pc ProcessingCode) String() string {
+	return zeroFill(pc.TransactionType, 2) + zeroFill(pc.FromAccount, 2) + zeroFill(pc.ToAccount, 2)
+}
+
+// GetProcessingCode parses DE 3 of iso.
+func GetProcessingCode(iso ISO8583Object) (ProcessingCode, error) {
+	pc, err := ParseProcessingCode(iso.GetField(3))
+	if err != nil {
+		return ProcessingCode{}, &FieldError{Field: 3, Err: err}
+	}
+	return pc, nil
+}
+
+// SetProcessingCode sets pc as DE 3 of iso after checking every part is at
+// most 2 digits.
+func SetProcessingCode(iso ISO8583Object, pc ProcessingCode) error {
+	for _, part := range []string{pc.TransactionType, pc.FromAccount, pc.ToAccount} {
+		if len(part) > 2 {
+			return &FieldError{Field: 3, Err: fmt.Errorf("%w: part %q longer than 2 digits", ErrProcessingCode, part)}
+		}
+	}
+	value := pc.String()
+	if _, err := ParseProcessingCode(value); err != nil {
+		return &FieldError{Field: 3, Err: err}
+	}
+	iso.SetField(3, value)
+	return nil
+}
@@ -0,0 +1,45 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProcessingCodeRoundTrip(t *testing.T) {
+	pc, err := ParseProcessingCode("401020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProcessingCode{TransactionType: TxTransfer, FromAccount: AccountSavings, ToAccount: AccountChecking}
+	if pc != want {
+		t.Errorf("ParseProcessingCode = %+v, want %+v", pc, want)
+	}
+	if got := pc.String(); got != "401020" {
+		t.Errorf("String = %q, want 401020", got)
+	}
+	if got := (ProcessingCode{TransactionType: TxBalanceInquiry}).String(); got != "310000" {
+		t.Errorf("String with empty accounts = %q, want 310000", got)
+	}
+
+	iso := NewDefaultPackager().NewMessage()
+	if err := SetProcessingCode(iso, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetProcessingCode(iso); err != nil || got != want {
+		t.Errorf("GetProcessingCode = %+v, %v, want %+v", got, err, want)
+	}
+}
+
+func TestProcessingCodeRejects(t *testing.T) {
+	for _, s := range []string{"", "31000", "3100000", "31A000"} {
+		if _, err := ParseProcessingCode(s); !errors.Is(err, ErrProcessingCode) {
+			t.Errorf("ParseProcessingCode(%q) error = %v, want ErrProcessingCode", s, err)
+		}
+	}
+	iso := NewDefaultPackager().NewMessage()
+	for _, pc := range []ProcessingCode{{TransactionType: "310"}, {FromAccount: "1A"}} {
+		if err := SetProcessingCode(iso, pc); !errors.Is(err, ErrProcessingCode) {
+			t.Errorf("SetProcessingCode(%+v) = %v, want ErrProcessingCode", pc, err)
+		}
+	}
+}
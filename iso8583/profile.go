@@ -0,0 +1,73 @@
+package iso8583
+
+import (
+	"errors"
+	"strings"
+)
+
+// Profile declares which fields a message must, may or must not carry. A
+// profile applies to messages with the given MTI whose DE 3 starts with
+// ProcessingCode (any DE 3 when empty). Profiles are declared in the spec:
+//
+//	Profiles:
+//	  - MTI: "0200"
+//	    ProcessingCode: "00"
+//	    Mandatory: [2, 3, 4, 7, 11, 41]
+//	    Conditional: [14, 35, 52]
+//	    Forbidden: [39]
+//	    Exclusive: true
+//
+// Conditional fields may be present or not. With Exclusive, any field that
+// is not Mandatory or Conditional is forbidden too.
+type Profile struct {
+	MTI            string `yaml:"MTI" json:"MTI"`
+	ProcessingCode string `yaml:"ProcessingCode" json:"ProcessingCode"`
+	Mandatory      []int  `yaml:"Mandatory" json:"Mandatory"`
+	Conditional    []int  `yaml:"Conditional" json:"Conditional"`
+	Forbidden      []int  `yaml:"Forbidden" json:"Forbidden"`
+	Exclusive      bool   `yaml:"Exclusive" json:"Exclusive"`
+}
+
+func (pr Profile) matches(iso ISO8583Object) bool {
+	return pr.MTI == iso.GetMTI() && strings.HasPrefix(iso.GetField(3), pr.ProcessingCode)
+}
+
+// ValidateProfile implements ISO8583Object. It checks the message against
+// every matching Profile of its spec and returns a *ValidationError listing
+// all violations, or nil when no profile is violated or none matches.
+func (p *isoObject) ValidateProfile() error {
+	verr := &ValidationError{}
+	for _, profile := range p.packager.Profiles {
+		if !profile.matches(p) {
+			continue
+		}
+
+		allowed := make(map[int]bool)
+		for _, field := range profile.Mandatory {
+			allowed[field] = true
+			if !p.HasField(field) {
+				verr.Fields = append(verr.Fields, &FieldError{Field: field, Err: errors.New("mandatory field missing")})
+			}
+		}
+		for _, field := range profile.Conditional {
+			allowed[field] = true
+		}
+		for _, field := range profile.Forbidden {
+			if p.HasField(field) {
+				verr.Fields = append(verr.Fields, &FieldError{Field: field, Err: errors.New("field not allowed")})
+			}
+		}
+		if profile.Exclusive {
+			for _, field := range p.Fields() {
+				if field > 0 && !allowed[field] {
+					verr.Fields = append(verr.Fields, &FieldError{Field: field, Err: errors.New("field not allowed")})
+				}
+			}
+		}
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
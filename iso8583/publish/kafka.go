@@ -0,0 +1,56 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// KafkaMessage is a message handed to a KafkaWriter.
+type KafkaMessage struct {
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// KafkaWriter writes messages to a topic. Wrap the client of your choice,
+// e.g. for segmentio/kafka-go:
+//
+//	type kafkaWriter struct{ w *kafka.Writer }
+//
+//	func (k kafkaWriter) WriteMessages(ctx context.Context, msgs ...publish.KafkaMessage) error {
+//		out := make([]kafka.Message, len(msgs))
+//		for i, m := range msgs {
+//			out[i] = kafka.Message{Key: m.Key, Value: m.Value, Time: m.Time}
+//		}
+//		return k.w.WriteMessages(ctx, out...)
+//	}
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...KafkaMessage) error
+}
+
+// Kafka is a Sink writing each record as a JSON message keyed by
+// Record.Key, so the request and response of a transaction land on the
+// same partition.
+type Kafka struct {
+	// Writer sends the messages to the topic.
+	Writer KafkaWriter
+}
+
+// NewKafka creates a publisher writing to w.
+func NewKafka(w KafkaWriter) *Publisher {
+	return New(&Kafka{Writer: w})
+}
+
+// Publish implements Sink.
+func (k *Kafka) Publish(ctx context.Context, records []*Record) error {
+	messages := make([]KafkaMessage, 0, len(records))
+	for _, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, KafkaMessage{Key: []byte(record.Key()), Value: value, Time: record.Time})
+	}
+	return k.Writer.WriteMessages(ctx, messages...)
+}
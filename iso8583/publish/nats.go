@@ -0,0 +1,55 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// NATSConn publishes a message on a subject. *nats.Conn satisfies it.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATS is a Sink publishing each record as JSON on
+// "<Subject>.<type>.<mti>", e.g. "iso8583.inbound.0200", so subscribers can
+// pick the traffic they need with wildcards such as "iso8583.*.0420" or
+// "iso8583.error.*".
+type NATS struct {
+	Conn NATSConn
+	// Subject is the subject prefix, "iso8583" when empty.
+	Subject string
+}
+
+// NewNATS creates a publisher publishing through conn under subject.
+func NewNATS(conn NATSConn, subject string) *Publisher {
+	return New(&NATS{Conn: conn, Subject: subject})
+}
+
+// Publish implements Sink. It publishes every record and returns the
+// errors joined.
+func (n *NATS) Publish(ctx context.Context, records []*Record) error {
+	prefix := n.Subject
+	if prefix == "" {
+		prefix = "iso8583"
+	}
+	var errs []error
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mti := record.MTI
+		if mti == "" {
+			mti = "unknown"
+		}
+		if err := n.Conn.Publish(prefix+"."+record.Type+"."+mti, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,257 @@
+// Package publish streams the traffic of an engine or client to a message
+// broker for fraud and analytics pipelines, without touching the handlers.
+// A Publisher is an iso8583.EventHook turning every event into a Record
+// and handing batches of them to a Sink, Kafka or NATS:
+//
+//	events := publish.NewKafka(kafkaWriter{&kafka.Writer{Addr: kafka.TCP("kafka:9092"), Topic: "iso8583"}})
+//	defer events.Close()
+//	engine.Events = events
+//
+//	events := publish.NewNATS(nc, "iso8583")
+//	client.Events = events
+//
+// Sensitive fields are masked before a record leaves the process and the
+// raw message is never published. The package depends on no broker client;
+// see KafkaWriter and NATSConn for the adapters.
+package publish
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+)
+
+const (
+	// DefaultBuffer is the number of records queued when Publisher.Buffer
+	// is not set.
+	DefaultBuffer = 4096
+	// DefaultBatchSize is the largest batch handed to the sink when
+	// Publisher.BatchSize is not set.
+	DefaultBatchSize = 100
+	// DefaultTimeout bounds a sink call when Publisher.Timeout is not set.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Record types.
+const (
+	TypeInbound  = "inbound"
+	TypeOutbound = "outbound"
+	TypeError    = "error"
+)
+
+// Record is the published form of an event, encoded as JSON.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Type is TypeInbound, TypeOutbound or TypeError.
+	Type string `json:"type"`
+	// Direction is "in" or "out", telling for an error which way the
+	// message was going.
+	Direction iso8583.Direction `json:"direction"`
+	// ConnID is the engine connection, zero for a client.
+	ConnID uint64 `json:"conn_id,omitempty"`
+	Peer   string `json:"peer,omitempty"`
+	MTI    string `json:"mti,omitempty"`
+	// Fields holds the masked fields keyed by number.
+	Fields map[string]string `json:"fields,omitempty"`
+	// Length is the size of the raw message.
+	Length int `json:"length"`
+	// Latency is the time since the request for an engine response, the
+	// round trip time for a client response.
+	Latency time.Duration `json:"latency_ns,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Key returns the RRN (DE 37) of the record, or its STAN (DE 11) when it
+// has none, so the request and response of a transaction share a key.
+func (r *Record) Key() string {
+	if rrn := r.Fields["37"]; rrn != "" {
+		return rrn
+	}
+	return r.Fields["11"]
+}
+
+// Sink delivers batches of records to a broker. The records slice is
+// reused after Publish returns.
+type Sink interface {
+	Publish(ctx context.Context, records []*Record) error
+}
+
+// Publisher is an iso8583.EventHook publishing every event to Sink from a
+// background goroutine, so the engine or client never waits on the broker.
+// When the queue is full new records are dropped and counted, see Dropped.
+type Publisher struct {
+	Sink Sink
+	// Buffer is the number of records queued. Defaults to DefaultBuffer.
+	Buffer int
+	// BatchSize is the largest batch handed to Sink. Defaults to
+	// DefaultBatchSize.
+	BatchSize int
+	// Timeout bounds each Sink call. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Mask redacts the published fields. Defaults to
+	// iso8583.DefaultMaskOptions.
+	Mask *iso8583.MaskOptions
+	// Logger receives sink errors. Defaults to slog.Default.
+	Logger *slog.Logger
+
+	once    sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *Record
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// New creates a publisher delivering to sink.
+func New(sink Sink) *Publisher {
+	return &Publisher{Sink: sink}
+}
+
+// OnInbound implements iso8583.EventHook.
+func (p *Publisher) OnInbound(ev iso8583.Event) {
+	p.enqueue(p.record(TypeInbound, ev, nil))
+}
+
+// OnOutbound implements iso8583.EventHook.
+func (p *Publisher) OnOutbound(ev iso8583.Event) {
+	p.enqueue(p.record(TypeOutbound, ev, nil))
+}
+
+// OnError implements iso8583.EventHook.
+func (p *Publisher) OnError(ev iso8583.Event, err error) {
+	p.enqueue(p.record(TypeError, ev, err))
+}
+
+// Dropped returns the number of records dropped because the queue was full
+// or the publisher closed.
+func (p *Publisher) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// Close stops accepting events and waits until the queued records are
+// delivered.
+func (p *Publisher) Close() error {
+	p.start()
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.done
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+	<-p.done
+	return nil
+}
+
+func (p *Publisher) record(typ string, ev iso8583.Event, err error) *Record {
+	record := &Record{
+		Time:      ev.Time.UTC(),
+		Type:      typ,
+		Direction: ev.Direction,
+		ConnID:    ev.ConnID,
+		Peer:      ev.Peer,
+		Length:    len(ev.Raw),
+		Latency:   ev.Latency,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if ev.Message == nil {
+		return record
+	}
+	opts := iso8583.DefaultMaskOptions
+	if p.Mask != nil {
+		opts = *p.Mask
+	}
+	iso := iso8583.Mask(ev.Message, opts)
+	record.MTI = iso.GetMTI()
+	record.Fields = make(map[string]string)
+	for _, field := range iso.Fields() {
+		// Field 0 dan 1 adalah MTI dan bitmap
+		if field > 1 {
+			record.Fields[strconv.Itoa(field)] = iso.GetField(field)
+		}
+	}
+	return record
+}
+
+func (p *Publisher) enqueue(record *Record) {
+	p.start()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.dropped.Add(1)
+		return
+	}
+	select {
+	case p.queue <- record:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+func (p *Publisher) start() {
+	p.once.Do(func() {
+		size := p.Buffer
+		if size <= 0 {
+			size = DefaultBuffer
+		}
+		p.queue = make(chan *Record, size)
+		p.done = make(chan struct{})
+		go p.run()
+	})
+}
+
+// run delivers the queue in batches until Close.
+func (p *Publisher) run() {
+	defer close(p.done)
+	size := p.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	batch := make([]*Record, 0, size)
+	for record := range p.queue {
+		batch = append(batch[:0], record)
+		// Ambil yang sudah antri tanpa menunggu, maksimal satu batch
+	fill:
+		for len(batch) < size {
+			select {
+			case record, ok := <-p.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, record)
+			default:
+				break fill
+			}
+		}
+		p.deliver(batch)
+	}
+}
+
+func (p *Publisher) deliver(batch []*Record) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := p.Sink.Publish(ctx, batch); err != nil {
+		p.log().Error("publish failed", "records", len(batch), "err", err)
+	}
+}
+
+// log returns Logger, or slog.Default when none is set.
+func (p *Publisher) log() *slog.Logger {
+	if p.Logger != nil {
+		return p.Logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,125 @@
+package iso8583
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// LimitPolicy decides whether the engine accepts a connection and the
+// requests read on it, so a misbehaving terminal cannot starve the others.
+type LimitPolicy interface {
+	// OpenConn is called for every accepted connection, before any TLS
+	// handshake. Returning false closes the connection. The ConnLimiter is
+	// closed once the connection ends.
+	OpenConn(remote net.Addr) (ConnLimiter, bool)
+}
+
+// ConnLimiter limits the requests of one connection.
+type ConnLimiter interface {
+	// AllowMessage reports whether a request may be handled; disallowed
+	// requests are dropped without a response.
+	AllowMessage() bool
+	Close()
+}
+
+// SourceLimits is a LimitPolicy limiting the connections per remote IP and
+// the requests per second per connection. Zero values mean no limit.
+type SourceLimits struct {
+	MaxConnsPerIP int
+	// MessagesPerSecond is the sustained request rate per connection,
+	// allowing bursts of up to Burst requests (default 1).
+	MessagesPerSecond float64
+	Burst             int
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+// OpenConn implements LimitPolicy.
+func (l *SourceLimits) OpenConn(remote net.Addr) (ConnLimiter, bool) {
+	ip := remote.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.MaxConnsPerIP > 0 && l.conns[ip] >= l.MaxConnsPerIP {
+		return nil, false
+	}
+	if l.conns == nil {
+		l.conns = make(map[string]int)
+	}
+	l.conns[ip]++
+
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &sourceConn{
+		limits: l,
+		ip:     ip,
+		rate:   l.MessagesPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}, true
+}
+
+func (l *SourceLimits) closeConn(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conns[ip]--; l.conns[ip] <= 0 {
+		delete(l.conns, ip)
+	}
+}
+
+// sourceConn is a token bucket for one connection.
+type sourceConn struct {
+	limits *SourceLimits
+	ip     string
+	rate   float64
+	burst  float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	closed bool
+}
+
+func (c *sourceConn) AllowMessage() bool {
+	if c.rate <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.tokens += now.Sub(c.last).Seconds() * c.rate
+	if c.tokens > c.burst {
+		c.tokens = c.burst
+	}
+	c.last = now
+	if c.tokens < 1 {
+		return false
+	}
+	c.tokens--
+	return true
+}
+
+func (c *sourceConn) Close() {
+	c.mu.Lock()
+	closed := c.closed
+	c.closed = true
+	c.mu.Unlock()
+	if !closed {
+		c.limits.closeConn(c.ip)
+	}
+}
+
+func closeLimiter(limiter ConnLimiter) {
+	if limiter != nil {
+		limiter.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+)
+
+// RawHandler handles a message the engine does not parse, e.g. a
+// proprietary sign-on frame. raw is the message without its length header.
+// The returned message is sent back framed with the engine LengthHeader;
+// nil sends nothing.
+type RawHandler func(raw []byte, conn ConnInfo) []byte
+
+type rawRoute struct {
+	match   func(raw []byte) bool
+	handler RawHandler
+}
+
+// AddRawHandler hands the messages match accepts to handler instead of
+// parsing and routing them. Raw handlers are tried in the order added and
+// run before MAC verification, network management, middleware and the
+// wire log. It may be called while the engine runs.
+//
+//	engine.AddRawHandler(func(raw []byte) bool {
+//		return bytes.HasPrefix(raw, []byte("SIGNON"))
+//	}, signOn)
+func (t *TCPIso8583Engine) AddRawHandler(match func(raw []byte) bool, handler RawHandler) {
+	t.rawMu.Lock()
+	defer t.rawMu.Unlock()
+	t.rawRoutes = append(t.rawRoutes, rawRoute{match: match, handler: handler})
+}
+
+// rawHandler returns the raw handler of message, nil when it is to be
+// parsed.
+func (t *TCPIso8583Engine) rawHandler(message []byte) RawHandler {
+	t.rawMu.RLock()
+	defer t.rawMu.RUnlock()
+	for _, route := range t.rawRoutes {
+		if route.match(message) {
+			return route.handler
+		}
+	}
+	return nil
+}
+
+// handleRaw runs handler on message and writes its answer back.
+func (t *TCPIso8583Engine) handleRaw(c io.Writer, writeMu *sync.Mutex, info *ConnInfo, message []byte, handler RawHandler) {
+	log := t.log().With("conn_id", info.ID)
+	w := &responseWriter{
+		w:       c,
+		writeMu: writeMu,
+		header:  t.LengthHeader,
+		timeout: t.WriteTimeout,
+		metrics: t.Metrics,
+		log:     log,
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("raw handler panic", "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+	resp := handler(message, *info)
+	if resp == nil {
+		return
+	}
+	if err := w.WriteRaw(resp); err != nil {
+		log.Error("write failed", "err", err)
+	}
+}
@@ -0,0 +1,278 @@
+// Package rc holds the DE 39 response codes of ISO 8583:1987 and their
+// classification, so applications stop comparing code strings by hand:
+//
+//	switch {
+//	case rc.IsApproved(code):
+//		...
+//	case rc.IsSystemError(code):
+//		// retry or reverse
+//	}
+//
+// The 3 digit action codes of ISO 8583:1993 are classified by their first
+// digit. Networks that use a code differently get a Table with overrides.
+package rc
+
+import "sync"
+
+// Standard 1987 response codes.
+const (
+	Approved                   = "00"
+	ReferToIssuer              = "01"
+	InvalidMerchant            = "03"
+	PickUpCard                 = "04"
+	DoNotHonor                 = "05"
+	Error                      = "06"
+	HonorWithID                = "08"
+	PartialApproval            = "10"
+	ApprovedVIP                = "11"
+	InvalidTransaction         = "12"
+	InvalidAmount              = "13"
+	InvalidCardNumber          = "14"
+	NoSuchIssuer               = "15"
+	ReEnterTransaction         = "19"
+	NoActionTaken              = "21"
+	FormatError                = "30"
+	LostCard                   = "41"
+	StolenCard                 = "43"
+	InsufficientFunds          = "51"
+	NoCheckingAccount          = "52"
+	NoSavingsAccount           = "53"
+	ExpiredCard                = "54"
+	IncorrectPIN               = "55"
+	NotPermittedToCardholder   = "57"
+	NotPermittedToTerminal     = "58"
+	SuspectedFraud             = "59"
+	ExceedsAmountLimit         = "61"
+	RestrictedCard             = "62"
+	SecurityViolation          = "63"
+	ExceedsFrequencyLimit      = "65"
+	ResponseReceivedTooLate    = "68"
+	PINTriesExceeded           = "75"
+	KeyExchangeValidationError = "88"
+	CutoffInProgress           = "90"
+	IssuerUnavailable          = "91"
+	RoutingError               = "92"
+	ViolationOfLaw             = "93"
+	DuplicateTransmission      = "94"
+	ReconcileError             = "95"
+	SystemMalfunction          = "96"
+)
+
+// Class is the outcome a response code stands for.
+type Class int
+
+const (
+	// ClassUnknown is a code the table does not know.
+	ClassUnknown Class = iota
+	// ClassApproved codes complete the transaction, possibly partially.
+	ClassApproved
+	// ClassDecline codes refuse the transaction for a business reason:
+	// retrying the same request is pointless.
+	ClassDecline
+	// ClassSystemError codes report a technical failure on the way:
+	// the outcome is uncertain and the request may be retried or reversed.
+	ClassSystemError
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassApproved:
+		return "approved"
+	case ClassDecline:
+		return "decline"
+	case ClassSystemError:
+		return "system error"
+	default:
+		return "unknown"
+	}
+}
+
+// Info describes a response code.
+type Info struct {
+	Class       Class
+	Description string
+}
+
+var standard = map[string]Info{
+	Approved:                   {ClassApproved, "approved"},
+	ReferToIssuer:              {ClassDecline, "refer to card issuer"},
+	InvalidMerchant:            {ClassDecline, "invalid merchant"},
+	PickUpCard:                 {ClassDecline, "pick up card"},
+	DoNotHonor:                 {ClassDecline, "do not honor"},
+	Error:                      {ClassSystemError, "error"},
+	HonorWithID:                {ClassApproved, "honor with identification"},
+	PartialApproval:            {ClassApproved, "approved for partial amount"},
+	ApprovedVIP:                {ClassApproved, "approved (VIP)"},
+	InvalidTransaction:         {ClassDecline, "invalid transaction"},
+	InvalidAmount:              {ClassDecline, "invalid amount"},
+	InvalidCardNumber:          {ClassDecline, "invalid card number"},
+	NoSuchIssuer:               {ClassDecline, "no such issuer"},
+	ReEnterTransaction:         {ClassSystemError, "re-enter transaction"},
+	NoActionTaken:              {ClassDecline, "no action taken"},
+	FormatError:                {ClassSystemError, "format error"},
+	LostCard:                   {ClassDecline, "lost card, pick up"},
+	StolenCard:                 {ClassDecline, "stolen card, pick up"},
+	InsufficientFunds:          {ClassDecline, "insufficient funds"},
+	NoCheckingAccount:          {ClassDecline, "no checking account"},
+	NoSavingsAccount:           {ClassDecline, "no savings account"},
+	ExpiredCard:                {ClassDecline, "expired card"},
+	IncorrectPIN:               {ClassDecline, "incorrect PIN"},
+	NotPermittedToCardholder:   {ClassDecline, "transaction not permitted to cardholder"},
+	NotPermittedToTerminal:     {ClassDecline, "transaction not permitted to terminal"},
+	SuspectedFraud:             {ClassDecline, "suspected fraud"},
+	ExceedsAmountLimit:         {ClassDecline, "exceeds withdrawal amount limit"},
+	RestrictedCard:             {ClassDecline, "restricted card"},
+	SecurityViolation:          {ClassDecline, "security violation"},
+	ExceedsFrequencyLimit:      {ClassDecline, "exceeds withdrawal frequency limit"},
+	ResponseReceivedTooLate:    {ClassSystemError, "response received too late"},
+	PINTriesExceeded:           {ClassDecline, "allowable number of PIN tries exceeded"},
+	KeyExchangeValidationError: {ClassSystemError, "key exchange validation error"},
+	CutoffInProgress:           {ClassSystemError, "cutoff in progress"},
+	IssuerUnavailable:          {ClassSystemError, "issuer or switch inoperative"},
+	RoutingError:               {ClassSystemError, "routing error"},
+	ViolationOfLaw:             {ClassDecline, "transaction cannot be completed, violation of law"},
+	DuplicateTransmission:      {ClassSystemError, "duplicate transmission"},
+	ReconcileError:             {ClassSystemError, "reconcile error"},
+	SystemMalfunction:          {ClassSystemError, "system malfunction"},
+}
+
+// Table classifies response codes: a network's overrides on top of the
+// standard codes. A Table is safe for concurrent use.
+type Table struct {
+	mu        sync.RWMutex
+	overrides map[string]Info
+}
+
+// NewTable creates a Table with overrides taking precedence over the
+// standard codes, e.g. a network using "06" as a decline:
+//
+//	table := rc.NewTable(map[string]rc.Info{
+//		"06": {Class: rc.ClassDecline, Description: "account blocked"},
+//	})
+func NewTable(overrides map[string]Info) *Table {
+	t := &Table{overrides: make(map[string]Info, len(overrides))}
+	for code, info := range overrides {
+		t.overrides[code] = info
+	}
+	return t
+}
+
+// Set adds or replaces the override for code.
+func (t *Table) Set(code string, info Info) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.overrides[code] = info
+}
+
+// Lookup returns what the table knows about code.
+func (t *Table) Lookup(code string) (Info, bool) {
+	t.mu.RLock()
+	info, ok := t.overrides[code]
+	t.mu.RUnlock()
+	if ok {
+		return info, true
+	}
+	if info, ok := standard[code]; ok {
+		return info, true
+	}
+	return actionCode(code)
+}
+
+// Classify returns the class of code, ClassUnknown for codes the table does
+// not know.
+func (t *Table) Classify(code string) Class {
+	info, _ := t.Lookup(code)
+	return info.Class
+}
+
+// Describe returns the description of code, or "" when unknown.
+func (t *Table) Describe(code string) string {
+	info, _ := t.Lookup(code)
+	return info.Description
+}
+
+// IsApproved reports whether code approves the transaction.
+func (t *Table) IsApproved(code string) bool { return t.Classify(code) == ClassApproved }
+
+// IsDecline reports whether code declines the transaction.
+func (t *Table) IsDecline(code string) bool { return t.Classify(code) == ClassDecline }
+
+// IsSystemError reports whether code reports a technical failure.
+func (t *Table) IsSystemError(code string) bool { return t.Classify(code) == ClassSystemError }
+
+// actionCode classifies a 3 digit ISO 8583:1993 action code by its first
+// digit: 0 approved, 1 and 2 declined (2 with pick up), 9 system related.
+// The acknowledgement codes of the file, reversal, reconciliation,
+// administrative and network management groups (x00) count as approved.
+func actionCode(code string) (Info, bool) {
+	if len(code) != 3 || code[0] < '0' || code[0] > '9' {
+		return Info{}, false
+	}
+	switch {
+	case code[0] == '0':
+		return Info{ClassApproved, "approved"}, true
+	case code[0] == '1':
+		return Info{ClassDecline, "denied"}, true
+	case code[0] == '2':
+		return Info{ClassDecline, "denied, pick up card"}, true
+	case code[0] == '9':
+		return Info{ClassSystemError, "system error"}, true
+	case code[1:] == "00":
+		return Info{ClassApproved, "accepted"}, true
+	default:
+		return Info{}, false
+	}
+}
+
+// Default is the Table with the standard codes only, used by the package
+// level functions.
+var Default = NewTable(nil)
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[string]*Table{
+		// Nama dialect sama dengan konstanta Dialect* di package iso8583
+		"visa-base1": NewTable(map[string]Info{
+			"85": {ClassApproved, "no reason to decline"},
+			"N7": {ClassDecline, "CVV2 mismatch"},
+			"1A": {ClassDecline, "additional customer authentication required"},
+		}),
+		"mastercard": NewTable(map[string]Info{
+			"85": {ClassApproved, "not declined"},
+		}),
+	}
+)
+
+// RegisterDialect makes table the one ForDialect returns for dialect, e.g.
+// for a private network spec.
+func RegisterDialect(dialect string, table *Table) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[dialect] = table
+}
+
+// ForDialect returns the Table of dialect, a name as passed to
+// iso8583.NewPackager, or Default when it has no overrides.
+func ForDialect(dialect string) *Table {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	if t, ok := dialects[dialect]; ok {
+		return t
+	}
+	return Default
+}
+
+// Classify returns the class of code in the Default table.
+func Classify(code string) Class { return Default.Classify(code) }
+
+// Describe returns the description of code in the Default table.
+func Describe(code string) string { return Default.Describe(code) }
+
+// IsApproved reports whether code approves the transaction.
+func IsApproved(code string) bool { return Default.IsApproved(code) }
+
+// IsDecline reports whether code declines the transaction.
+func IsDecline(code string) bool { return Default.IsDecline(code) }
+
+// IsSystemError reports whether code reports a technical failure.
+func IsSystemError(code string) bool { return Default.IsSystemError(code) }
@@ -0,0 +1,99 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSignOnDeclined is returned by Connect when the host does not approve
+// the sign-on.
+var ErrSignOnDeclined = errors.New("iso8583 sign-on declined")
+
+// ConnState is the state of an ISOClient connection.
+type ConnState int
+
+const (
+	StateDisconnected ConnState = iota
+	StateConnecting
+	StateConnected
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	default:
+		return "disconnected"
+	}
+}
+
+func (c *ISOClient) setState(state ConnState, err error) {
+	c.state.Store(int32(state))
+	if c.OnStateChange != nil {
+		c.OnStateChange(state, err)
+	}
+}
+
+// reconnect redials with exponential backoff until it succeeds or the
+// client is closed.
+func (c *ISOClient) reconnect(closeCh chan struct{}) {
+	delay := c.ReconnectMinDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	maxDelay := c.ReconnectMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = time.Minute
+	}
+
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-closeCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		c.setState(StateConnecting, nil)
+		err := c.connect()
+		if err == nil {
+			return
+		}
+		c.log().Warn("reconnect failed", "address", c.Address, "retry_in", delay*2, "err", err)
+		c.setState(StateDisconnected, err)
+
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+// signOn sends the SignOn message, if any, and waits for an approving
+// response.
+func (c *ISOClient) signOn() error {
+	if c.SignOn == nil {
+		return nil
+	}
+
+	iso, err := c.SignOn()
+	if err != nil {
+		return err
+	}
+	resp, err := c.Send(iso)
+	if err != nil {
+		return err
+	}
+	accepted := resp.GetField(39) == approvalCode(iso.GetMTI())
+	if c.SignOnAccept != nil {
+		accepted = c.SignOnAccept(resp)
+	}
+	if !accepted {
+		return fmt.Errorf("%w: DE 39 %q", ErrSignOnDeclined, resp.GetField(39))
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package iso8583
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverHandler stops a panicking handler from killing the connection
+// goroutine. It logs the panic with its stack and, when PanicResponseCode is
+// set and nothing was sent yet, answers the request with that DE 39.
+func (t *TCPIso8583Engine) recoverHandler(w *responseWriter, iso ISO8583Object) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	w.log.Error("handler panic", "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+
+	if t.PanicResponseCode == "" || w.handled() {
+		return
+	}
+	resp := NewResponseFrom(iso)
+	resp.SetField(39, t.PanicResponseCode)
+	if err := w.Write(resp); err != nil {
+		w.log.Error("write failed", "err", err)
+	}
+}
@@ -0,0 +1,84 @@
+package iso8583
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// active returns the spec new messages are bound to: the last one loaded by
+// Reload, or pk itself when it was never reloaded.
+func (pk *Packager) active() *Packager {
+	if next := pk.next.Load(); next != nil {
+		return next
+	}
+	return pk
+}
+
+// Reload reads the spec at path and swaps it in for every message created
+// from now on. Messages created before keep the spec they were created with,
+// so in-flight requests finish parsing and composing with it. On error the
+// current spec stays in place.
+//
+// The reloaded spec replaces pk's options too: Lenient, TimeZone and the
+// like set in code on pk no longer apply, so put them in the spec file.
+func (pk *Packager) Reload(path string) error {
+	next, err := LoadSpec(path)
+	if err != nil {
+		return err
+	}
+	pk.next.Store(next)
+	pk.generation.Add(1)
+	return nil
+}
+
+// Generation returns how many times pk was reloaded, e.g. to expose the
+// active spec version on a health endpoint.
+func (pk *Packager) Generation() uint64 {
+	return pk.generation.Load()
+}
+
+// Watch polls path every interval and calls Reload when its modification
+// time or size changes, until ctx is done. Reload errors are passed to
+// onError, which may be nil; the current spec stays active until the file
+// loads again. Run it in its own goroutine:
+//
+//	go packager.Watch(ctx, "isopackager.yml", 10*time.Second, func(err error) {
+//		log.Println("spec reload failed:", err)
+//	})
+func (pk *Packager) Watch(ctx context.Context, path string, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			continue
+		}
+		if info.ModTime().Equal(modTime) && info.Size() == size {
+			continue
+		}
+		// Dicatat dulu supaya file yang gagal di-load tidak dicoba terus
+		modTime, size = info.ModTime(), info.Size()
+		if err := pk.Reload(path); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package iso8583
+
+// DefaultEchoFields are the request fields NewResponseFrom copies into the
+// response when no fields are given: transmission date & time, STAN, local
+// time, local date, RRN, terminal ID and merchant ID.
+var DefaultEchoFields = []int{7, 11, 12, 13, 37, 41, 42}
+
+// NewResponseFrom builds a fresh response for request: the MTI is flipped to
+// its response (0200 -> 0210, 0800 -> 0810, 1804 -> 1814) and echoFields, or
+// DefaultEchoFields when omitted, are copied over when present. The header
+// and trailer are kept. The request is left untouched; the caller only
+// needs to set DE 39 and any extra data.
+func NewResponseFrom(request ISO8583Object, echoFields ...int) ISO8583Object {
+	if len(echoFields) == 0 {
+		echoFields = DefaultEchoFields
+	}
+
+	response := request.Clone()
+	response.Clear()
+	response.SetMTI(responseMTI(request.GetMTI()))
+	CopyFields(response, request, echoFields...)
+	return response
+}
+
+// CopyFields copies fields from src to dst where src has them, e.g. echoing
+// request data into a response built from scratch. Fields missing from src
+// are left untouched in dst.
+func CopyFields(dst, src ISO8583Object, fields ...int) {
+	for _, field := range fields {
+		copyField(dst, src, field)
+	}
+}
+
+// copyField copies field from src to dst, keeping present-but-empty fields
+// present.
+func copyField(dst, src ISO8583Object, field int) {
+	if !src.HasField(field) {
+		return
+	}
+	if value := src.GetField(field); value != "" {
+		dst.SetField(field, value)
+	} else {
+		dst.SetEmptyField(field)
+	}
+}
+
+// isRequestMTI reports whether the message function digit denotes a request
+// or an advice (even), as opposed to a response (odd).
+func isRequestMTI(mti string) bool {
+	return len(mti) == 4 && (mti[2]-'0')%2 == 0
+}
+
+// responseMTI returns the response MTI for a request, e.g. 0200 -> 0210,
+// 1420 -> 1430 and 1804 -> 1814. The response to a repeat drops the repeat
+// flag of the message origin digit (0201 -> 0210, 1423 -> 1432).
+func responseMTI(mti string) string {
+	if len(mti) != 4 || !isRequestMTI(mti) {
+		return mti
+	}
+	origin := mti[3]
+	if origin >= '0' && origin <= '5' {
+		origin = '0' + (origin-'0')&^1
+	}
+	return mti[:2] + string(mti[2]+1) + string(origin)
+}
@@ -0,0 +1,138 @@
+package iso8583
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ResponseWriter lets a handler decide what goes back to the client. A handler
+// may write a different message than the request, several messages (e.g. an
+// advice followed by the response) or nothing at all. When a handler neither
+// writes nor discards, the engine sends the request object back, composed
+// with whatever the handler set on it.
+type ResponseWriter interface {
+	// Write composes iso and sends it to the client.
+	Write(iso ISO8583Object) error
+	// WriteRaw sends an already composed message, framed with the engine
+	// LengthHeader.
+	WriteRaw(message []byte) error
+	// Discard suppresses the default response.
+	Discard()
+}
+
+type responseWriter struct {
+	w       io.Writer
+	writeMu *sync.Mutex
+	header  LengthHeader
+	timeout time.Duration
+	metrics *Metrics
+	span    Span
+	log     *slog.Logger
+	// onWrite is called with every message sent.
+	onWrite func(message []byte)
+	// onError is called with every message that failed to compose or
+	// write, iso or message being nil when unknown.
+	onError func(iso ISO8583Object, message []byte, err error)
+	mac     *MACConfig
+	// requestTPDU is the TPDU of the request when the engine swaps TPDUs.
+	requestTPDU string
+	// request dan echoFields untuk EchoFields engine
+	request    ISO8583Object
+	echoFields []int
+
+	mu        sync.Mutex
+	written   bool
+	discarded bool
+}
+
+func (r *responseWriter) Write(iso ISO8583Object) error {
+	message, err := r.compose(iso)
+	if err != nil {
+		r.failed(iso, nil, err)
+		return err
+	}
+	r.metrics.responseCode(iso)
+	r.responseCode(iso)
+	return r.WriteRaw(message)
+}
+
+// responseCode records the DE 39 of a response on the message span.
+func (r *responseWriter) responseCode(iso ISO8583Object) {
+	if r.span == nil {
+		return
+	}
+	if rc := iso.GetField(39); rc != "" {
+		r.span.SetAttribute(AttrResponseCode, rc)
+	}
+}
+
+// compose composes iso, stamping its MAC first when the engine MACs
+// responses, swapping the TPDU addresses and echoing request fields when
+// asked to.
+func (r *responseWriter) compose(iso ISO8583Object) ([]byte, error) {
+	replyTPDU(iso, r.requestTPDU)
+	r.echo(iso)
+	if r.mac != nil && !r.mac.exempt(iso) {
+		if err := StampMAC(iso, *r.mac); err != nil {
+			return nil, err
+		}
+	}
+	return iso.ComposeBytes()
+}
+
+// echo copies the engine EchoFields missing from the response iso over from
+// the request. Requests and advices the handler sends are left alone.
+func (r *responseWriter) echo(iso ISO8583Object) {
+	if r.request == nil || iso == r.request || isRequestMTI(iso.GetMTI()) {
+		return
+	}
+	for _, field := range r.echoFields {
+		if !iso.HasField(field) {
+			copyField(iso, r.request, field)
+		}
+	}
+}
+
+func (r *responseWriter) WriteRaw(message []byte) error {
+	r.mu.Lock()
+	r.written = true
+	r.mu.Unlock()
+
+	// Koneksi bisa dipakai bersama oleh beberapa handler (keep-alive)
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+	if dl, ok := r.w.(interface{ SetWriteDeadline(time.Time) error }); ok && r.timeout > 0 {
+		_ = dl.SetWriteDeadline(time.Now().Add(r.timeout))
+	}
+	if err := r.header.writeFrame(r.w, message); err != nil {
+		r.failed(nil, message, err)
+		return err
+	}
+	r.metrics.sent()
+	if r.onWrite != nil {
+		r.onWrite(message)
+	}
+	return nil
+}
+
+// failed reports a message that could not be sent to onError.
+func (r *responseWriter) failed(iso ISO8583Object, message []byte, err error) {
+	if r.onError != nil {
+		r.onError(iso, message, err)
+	}
+}
+
+func (r *responseWriter) Discard() {
+	r.mu.Lock()
+	r.discarded = true
+	r.mu.Unlock()
+}
+
+// handled reports whether the handler took care of the response itself.
+func (r *responseWriter) handled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.written || r.discarded
+}
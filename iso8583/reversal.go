@@ -0,0 +1,113 @@
+package iso8583
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultReversalFields are the fields BuildReversal copies from the
+// original: PAN, processing code, amounts, STAN, local date and time,
+// expiry, merchant type, POS entry mode, card sequence number, acquirer and
+// forwarder IDs, RRN, terminal and merchant data and currencies.
+var DefaultReversalFields = []int{2, 3, 4, 5, 6, 11, 12, 13, 14, 18, 22, 23, 32, 33, 37, 41, 42, 43, 49, 50, 51}
+
+// ReversalOptions tunes BuildReversalWithOptions for the rules of a network.
+type ReversalOptions struct {
+	// MTI of the reversal. Defaults to 0400, or 0420 with Advice, for a
+	// 1987 original and to 1420 (1993 has no reversal request) otherwise.
+	MTI string
+	// Advice makes the 1987 default MTI 0420 instead of 0400.
+	Advice bool
+	// Fields are copied from the original. Defaults to
+	// DefaultReversalFields.
+	Fields []int
+	// OriginalDataField carries the original data elements. Defaults to
+	// DE 90 for a 1987 original and DE 56 otherwise.
+	OriginalDataField int
+	// ResponseCode, when set, goes to DE 39 as the reversal reason, e.g.
+	// "68" after a timeout.
+	ResponseCode string
+}
+
+// BuildReversal builds the reversal of original with the default
+// ReversalOptions, see BuildReversalWithOptions.
+func BuildReversal(original ISO8583Object) (ISO8583Object, error) {
+	return BuildReversalWithOptions(original, ReversalOptions{})
+}
+
+// BuildReversalWithOptions builds the reversal of original, an authorization
+// or financial request or advice (e.g. a completion advice): opts.Fields
+// are copied over, DE 7 is stamped with the current UTC time and the
+// original data elements are assembled from the original MTI, STAN (DE 11),
+// transmission date and time (DE 7, or the local date and time DE 12 for
+// 1993) and acquirer and forwarder IDs (DE 32 and 33). The header and
+// trailer are kept. The original is left untouched.
+func BuildReversalWithOptions(original ISO8583Object, opts ReversalOptions) (ISO8583Object, error) {
+	mti := original.GetMTI()
+	version := MTIVersion(mti)
+	if version == "" || !isRequestMTI(mti) || (mti[1] != '1' && mti[1] != '2') {
+		return nil, fmt.Errorf("cannot reverse MTI %q: not an authorization or financial request or advice", mti)
+	}
+
+	if opts.MTI == "" {
+		switch {
+		case version != Version1987:
+			opts.MTI = mti[:1] + "420"
+		case opts.Advice:
+			opts.MTI = "0420"
+		default:
+			opts.MTI = "0400"
+		}
+	}
+	if opts.Fields == nil {
+		opts.Fields = DefaultReversalFields
+	}
+	if opts.OriginalDataField == 0 {
+		opts.OriginalDataField = 90
+		if version != Version1987 {
+			opts.OriginalDataField = 56
+		}
+	}
+
+	reversal := original.Clone()
+	reversal.Clear()
+	reversal.SetMTI(opts.MTI)
+	CopyFields(reversal, original, opts.Fields...)
+	if original.HasField(7) {
+		if err := reversal.SetTransmissionTime(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ResponseCode != "" {
+		reversal.SetField(39, opts.ResponseCode)
+	}
+	reversal.SetField(opts.OriginalDataField, originalDataElements(original, version))
+	return reversal, nil
+}
+
+// originalDataElements assembles the original data elements of original:
+// for 1987 the fixed n 42 of DE 90 (MTI, STAN, DE 7, DE 32 and DE 33, the IDs
+// zero filled to 11 digits), for 1993 the DE 56 layout (MTI, STAN, DE 12 and
+// the LL prefixed acquirer ID).
+func originalDataElements(original ISO8583Object, version string) string {
+	if version == Version1987 {
+		return OriginalDataOf(original).String()
+	}
+	var sb strings.Builder
+	sb.WriteString(original.GetMTI())
+	sb.WriteString(zeroFill(original.GetField(11), 6))
+	sb.WriteString(zeroFill(original.GetField(12), 12))
+	acquirer := original.GetField(32)
+	fmt.Fprintf(&sb, "%02d%s", len(acquirer), acquirer)
+	return sb.String()
+}
+
+// zeroFill right justifies value in width digits, keeping the last width
+// characters of a longer value.
+func zeroFill(value string, width int) string {
+	if len(value) >= width {
+		return value[len(value)-width:]
+	}
+	return strings.Repeat("0", width-len(value)) + value
+}
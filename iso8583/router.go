@@ -0,0 +1,180 @@
+package iso8583
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Route matches requests to a handler. Every non-empty criterion must match:
+// MTI exactly, DE 3 by prefix and the custom Match predicate. A route with no
+// criteria matches every request.
+type Route struct {
+	// Name identifies the route for Remove. Handling a route with the name
+	// of an existing one replaces it.
+	Name           string
+	MTI            string
+	ProcessingCode string
+	Match          func(iso ISO8583Object) bool
+	// Priority orders the routes; higher priorities are tried first and
+	// routes with the same priority are tried in registration order.
+	Priority int
+	Handler  TcpHandler
+
+	// key is the concatenated field value of routes added through
+	// AddHandler, so re-adding a key replaces the route.
+	key *string
+}
+
+// RoutingKeyFunc computes the key a request is matched against the
+// AddHandler routes with, e.g. MTI + DE 3 + DE 24 or any business rule.
+type RoutingKeyFunc func(iso ISO8583Object) string
+
+// FieldsKey returns the RoutingKeyFunc concatenating the values of fields,
+// the key the engine FieldNumber computes.
+func FieldsKey(fields ...int) RoutingKeyFunc {
+	return func(iso ISO8583Object) string {
+		return joinFields(iso, fields)
+	}
+}
+
+// matches reports whether rt routes iso. key is the AddHandler key of iso,
+// "" with hasKey false when none of its keys has a route.
+func (rt *Route) matches(iso ISO8583Object, key string, hasKey bool) bool {
+	if rt.MTI != "" && rt.MTI != iso.GetMTI() {
+		return false
+	}
+	if rt.ProcessingCode != "" && !strings.HasPrefix(iso.GetField(3), rt.ProcessingCode) {
+		return false
+	}
+	if rt.key != nil && (!hasKey || *rt.key != key) {
+		return false
+	}
+	return rt.Match == nil || rt.Match(iso)
+}
+
+// Router picks the handler of the first matching Route. Requests no route
+// matches go to NotFound. A Router is safe for concurrent use: routes can be
+// added and removed while the engine serves, e.g. to switch processing
+// codes behind a feature flag.
+type Router struct {
+	mu       sync.RWMutex
+	routes   []*Route
+	notFound TcpHandler
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle adds a route, replacing the route of the same Name if any.
+func (r *Router) Handle(route Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if route.Name != "" {
+		r.remove(func(rt *Route) bool { return rt.Name == route.Name })
+	}
+	r.add(&route)
+}
+
+// Remove removes the route named name and reports whether there was one.
+func (r *Router) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.remove(func(rt *Route) bool { return rt.Name == name }) > 0
+}
+
+// RemoveMTI removes the routes added by HandleMTI with the given MTI and
+// DE 3 prefix, returning how many were removed.
+func (r *Router) RemoveMTI(mti, processingCode string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.remove(func(rt *Route) bool {
+		return rt.Name == "" && rt.key == nil && rt.Match == nil && rt.MTI == mti && rt.ProcessingCode == processingCode
+	})
+}
+
+// HandleMTI routes requests with the given MTI and DE 3 prefix to handler.
+func (r *Router) HandleMTI(mti, processingCode string, handler TcpHandler) {
+	r.Handle(Route{MTI: mti, ProcessingCode: processingCode, Handler: handler})
+}
+
+// NotFound sets the handler for requests no route matches.
+func (r *Router) NotFound(handler TcpHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notFound = handler
+}
+
+// lookup returns the handler for iso, falling back to the NotFound handler.
+// keys are the AddHandler keys of iso in order of preference: AddHandler
+// routes match the first of them that has a route.
+func (r *Router) lookup(iso ISO8583Object, keys []string) TcpHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, hasKey := r.firstKey(keys)
+	for _, route := range r.routes {
+		if route.matches(iso, key, hasKey) {
+			return route.Handler
+		}
+	}
+	return r.notFound
+}
+
+// firstKey returns the first of keys an AddHandler route was added for. It
+// is called with r.mu locked.
+func (r *Router) firstKey(keys []string) (string, bool) {
+	for _, key := range keys {
+		for _, route := range r.routes {
+			if route.key != nil && *route.key == key {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// handleKey adds or replaces the route for a concatenated field value key.
+func (r *Router) handleKey(key string, handler TcpHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, route := range r.routes {
+		if route.key != nil && *route.key == key {
+			route.Handler = handler
+			return
+		}
+	}
+	r.add(&Route{Handler: handler, key: &key})
+}
+
+// removeKey removes the route for a concatenated field value key.
+func (r *Router) removeKey(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.remove(func(rt *Route) bool { return rt.key != nil && *rt.key == key }) > 0
+}
+
+// remove drops the routes matching fn, returning how many were dropped. It
+// is called with r.mu locked.
+func (r *Router) remove(fn func(rt *Route) bool) int {
+	n := len(r.routes)
+	r.routes = slices.DeleteFunc(r.routes, fn)
+	return n - len(r.routes)
+}
+
+func (r *Router) add(route *Route) {
+	r.routes = append(r.routes, route)
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return r.routes[i].Priority > r.routes[j].Priority
+	})
+}
+
+func joinFields(iso ISO8583Object, fieldNumber []int) string {
+	var fieldValues []string
+	for _, field := range fieldNumber {
+		fieldValues = append(fieldValues, iso.GetField(field))
+	}
+	return strings.Join(fieldValues, "")
+}
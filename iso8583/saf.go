@@ -0,0 +1,374 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSAFQueued is returned by SendAdvice when the host could not be reached
+// and the message was stored for forwarding.
+var ErrSAFQueued = errors.New("iso8583 message stored for forwarding")
+
+// errSAFBacklog is the LastError of a message queued behind older entries.
+var errSAFBacklog = errors.New("queued behind older messages")
+
+// SAFEntry is a message waiting in a store-and-forward queue.
+type SAFEntry struct {
+	ID string `json:"id"`
+	// Message is the composed message, header and trailer included.
+	Message []byte    `json:"message"`
+	Created time.Time `json:"created"`
+	// Sent reports whether the message was written to the host before and
+	// may have been processed, in which case it is forwarded with the
+	// repeat MTI (e.g. 0421). Messages that never left the process, e.g.
+	// while disconnected, keep the original MTI.
+	Sent bool `json:"sent"`
+	// Attempts counts the negative acknowledgements received.
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// SAFStore persists the store-and-forward queue, e.g. in files (see
+// FileSAFStore) or a database table. Implementations must be safe for
+// concurrent use.
+type SAFStore interface {
+	// Put adds a new entry.
+	Put(entry SAFEntry) error
+	// Pending returns every entry, oldest first.
+	Pending() ([]SAFEntry, error)
+	// Update saves the sent flag, attempt count and error of an entry.
+	Update(entry SAFEntry) error
+	// Remove deletes an entry once it is acknowledged or dropped.
+	Remove(id string) error
+}
+
+// SAF is the store-and-forward setting of an ISOClient. Advices and
+// reversals sent with SendAdvice while the host is unreachable are stored
+// and forwarded, oldest first, once the client is connected again.
+type SAF struct {
+	Store SAFStore
+	// RetryInterval is how often the queue is retried while connected.
+	// Defaults to 30s. The queue is also flushed right after every connect.
+	RetryInterval time.Duration
+	// MaxAttempts drops an entry after that many negative acknowledgements;
+	// zero retries forever. Timeouts and connection errors do not count
+	// against it.
+	MaxAttempts int
+	// Accept decides whether a response acknowledges the entry. The default
+	// accepts any response: the host has the advice.
+	Accept func(resp ISO8583Object) bool
+	// OnDrop is called with entries dropped after MaxAttempts.
+	OnDrop func(entry SAFEntry)
+
+	seq      atomic.Uint64
+	wakeOnce sync.Once
+	wake     chan struct{}
+	loopMu   sync.Mutex
+	loopCh   chan struct{}
+	// flushMu menjaga supaya urutan forward tidak disusul flush lain
+	flushMu sync.Mutex
+}
+
+// SendAdvice sends an advice or reversal and waits for its response. When
+// the client has a SAF and the host cannot be reached or does not answer in
+// time, the message is stored for forwarding and ErrSAFQueued is returned.
+// While older messages are still waiting in the SAF the message is queued
+// behind them, so the host gets them in order. Other errors, e.g. a message
+// that does not compose, are returned as is.
+func (c *ISOClient) SendAdvice(iso ISO8583Object) (ISO8583Object, error) {
+	message, err := iso.ComposeBytes()
+	if err != nil {
+		return nil, err
+	}
+	if c.SAF != nil {
+		if entries, err := c.SAF.Store.Pending(); err == nil && len(entries) > 0 {
+			if err := c.SAF.put(message, errSAFBacklog, false); err != nil {
+				return nil, fmt.Errorf("store for forwarding: %w", err)
+			}
+			c.wakeSAF()
+			return nil, ErrSAFQueued
+		}
+	}
+	resp, err := c.Send(iso)
+	if err == nil || c.SAF == nil || !isLinkError(err) {
+		return resp, err
+	}
+	if perr := c.SAF.put(message, err, written(err)); perr != nil {
+		return nil, fmt.Errorf("store for forwarding: %w (send: %v)", perr, err)
+	}
+	return nil, ErrSAFQueued
+}
+
+// isLinkError reports whether err means the host did not get or answer the
+// message, as opposed to a message that cannot be sent at all.
+func isLinkError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, ErrResponseTimeout) || errors.Is(err, ErrClientClosed) ||
+		errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// written reports whether a failed Send wrote the message before failing,
+// so the host may have processed it.
+func written(err error) bool {
+	return errors.Is(err, ErrResponseTimeout)
+}
+
+func (s *SAF) put(message []byte, cause error, sent bool) error {
+	now := time.Now()
+	entry := SAFEntry{
+		// ID urut waktu supaya FileSAFStore bisa mengurutkan nama file
+		ID:        fmt.Sprintf("%020d-%06d", now.UnixNano(), s.seq.Add(1)%1000000),
+		Message:   message,
+		Created:   now,
+		Sent:      sent,
+		LastError: cause.Error(),
+	}
+	return s.Store.Put(entry)
+}
+
+// startSAF starts forwarding the queue until closeCh is closed. Connect
+// calls it every time; only one loop runs per closeCh.
+func (c *ISOClient) startSAF(closeCh chan struct{}) {
+	s := c.SAF
+	if s == nil || closeCh == nil {
+		return
+	}
+	s.wakeOnce.Do(func() { s.wake = make(chan struct{}, 1) })
+	s.loopMu.Lock()
+	if s.loopCh == closeCh {
+		s.loopMu.Unlock()
+		return
+	}
+	s.loopCh = closeCh
+	s.loopMu.Unlock()
+
+	interval := s.RetryInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closeCh:
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+			if c.Connected() {
+				c.flushSAF()
+			}
+		}
+	}()
+}
+
+// wakeSAF asks the forwarding loop to flush now, e.g. after a connect.
+func (c *ISOClient) wakeSAF() {
+	if c.SAF == nil {
+		return
+	}
+	c.SAF.wakeOnce.Do(func() { c.SAF.wake = make(chan struct{}, 1) })
+	select {
+	case c.SAF.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flushSAF forwards the stored entries in order. It stops at the first link
+// error so the order is kept for the next attempt.
+func (c *ISOClient) flushSAF() {
+	s := c.SAF
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	entries, err := s.Store.Pending()
+	if err != nil {
+		c.log().Error("SAF load failed", "address", c.Address, "err", err)
+		return
+	}
+	for _, entry := range entries {
+		iso, err := c.newMessage()
+		if err == nil {
+			err = iso.ParseBytes(entry.Message)
+		}
+		if err != nil {
+			c.log().Error("SAF entry dropped: parse failed", "id", entry.ID, "err", err)
+			s.drop(entry)
+			continue
+		}
+		if entry.Sent {
+			iso.SetMTI(repeatMTI(iso.GetMTI()))
+		}
+
+		resp, err := c.Send(iso)
+		switch {
+		case err != nil && isLinkError(err):
+			entry.Sent = entry.Sent || written(err)
+			entry.LastError = err.Error()
+			_ = s.Store.Update(entry)
+			return
+		case err != nil:
+			entry.Attempts++
+			entry.LastError = err.Error()
+		case s.Accept == nil || s.Accept(resp):
+			if err := s.Store.Remove(entry.ID); err != nil {
+				c.log().Error("SAF remove failed", "id", entry.ID, "err", err)
+			}
+			continue
+		default:
+			entry.Sent = true
+			entry.Attempts++
+			entry.LastError = "negative acknowledgement, DE 39 " + resp.GetField(39)
+		}
+
+		if s.MaxAttempts > 0 && entry.Attempts >= s.MaxAttempts {
+			c.log().Warn("SAF entry dropped", "id", entry.ID, "attempts", entry.Attempts, "err", entry.LastError)
+			s.drop(entry)
+			continue
+		}
+		if err := s.Store.Update(entry); err != nil {
+			c.log().Error("SAF update failed", "id", entry.ID, "err", err)
+		}
+	}
+}
+
+func (s *SAF) drop(entry SAFEntry) {
+	_ = s.Store.Remove(entry.ID)
+	if s.OnDrop != nil {
+		s.OnDrop(entry)
+	}
+}
+
+// repeatMTI returns the repeat form of mti, e.g. 0420 -> 0421 and 0220 ->
+// 0221. MTIs already marked as repeat are returned as is.
+func repeatMTI(mti string) string {
+	if len(mti) != 4 || mti[3] < '0' || mti[3] > '5' || (mti[3]-'0')%2 == 1 {
+		return mti
+	}
+	return mti[:3] + string(mti[3]+1)
+}
+
+// MemorySAFStore keeps the queue in memory, for tests or when losing the
+// queue on restart is acceptable.
+type MemorySAFStore struct {
+	mu      sync.Mutex
+	entries []SAFEntry
+}
+
+// Put implements SAFStore.
+func (m *MemorySAFStore) Put(entry SAFEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Pending implements SAFStore.
+func (m *MemorySAFStore) Pending() ([]SAFEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SAFEntry(nil), m.entries...), nil
+}
+
+// Update implements SAFStore.
+func (m *MemorySAFStore) Update(entry SAFEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.entries {
+		if m.entries[i].ID == entry.ID {
+			m.entries[i] = entry
+			return nil
+		}
+	}
+	return fmt.Errorf("SAF entry %s not found", entry.ID)
+}
+
+// Remove implements SAFStore.
+func (m *MemorySAFStore) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.entries {
+		if m.entries[i].ID == id {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// FileSAFStore keeps every entry as a JSON file in Dir, which is created on
+// first use. Files are written to a temporary name and renamed, so a crash
+// never leaves a half written entry.
+type FileSAFStore struct {
+	Dir string
+}
+
+func (f FileSAFStore) path(id string) string {
+	return filepath.Join(f.Dir, id+".json")
+}
+
+// Put implements SAFStore.
+func (f FileSAFStore) Put(entry SAFEntry) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	return f.Update(entry)
+}
+
+// Pending implements SAFStore.
+func (f FileSAFStore) Pending() ([]SAFEntry, error) {
+	names, err := filepath.Glob(filepath.Join(f.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	entries := make([]SAFEntry, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry SAFEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Update implements SAFStore.
+func (f FileSAFStore) Update(entry SAFEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp := f.path(entry.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(entry.ID))
+}
+
+// Remove implements SAFStore.
+func (f FileSAFStore) Remove(id string) error {
+	err := os.Remove(f.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package iso8583
+
+import (
+	"net"
+	"sync"
+)
+
+// queuedWrite is a message waiting in a sendQueue.
+type queuedWrite struct {
+	message []byte
+	done    chan error
+}
+
+// sendQueue writes the messages of one connection from a single goroutine,
+// priority messages first.
+type sendQueue struct {
+	high   chan queuedWrite
+	normal chan queuedWrite
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newSendQueue(size int) *sendQueue {
+	return &sendQueue{
+		high:   make(chan queuedWrite, size),
+		normal: make(chan queuedWrite, size),
+		done:   make(chan struct{}),
+	}
+}
+
+// stop ends the writer and fails every queued message. It is called when
+// the connection is gone.
+func (q *sendQueue) stop() {
+	q.once.Do(func() { close(q.done) })
+}
+
+// run writes queued messages to conn until stop.
+func (q *sendQueue) run(conn net.Conn, header LengthHeader) {
+	for {
+		var w queuedWrite
+		// Antrian prioritas selalu dikosongkan dulu
+		select {
+		case w = <-q.high:
+		default:
+			select {
+			case w = <-q.high:
+			case w = <-q.normal:
+			case <-q.done:
+				return
+			}
+		}
+		w.done <- header.writeFrame(conn, w.message)
+	}
+}
+
+// write queues message and waits until it is written.
+func (q *sendQueue) write(message []byte, priority bool) error {
+	w := queuedWrite{message: message, done: make(chan error, 1)}
+	ch := q.normal
+	if priority {
+		ch = q.high
+	}
+	select {
+	case ch <- w:
+	case <-q.done:
+		return ErrClientClosed
+	}
+	select {
+	case err := <-w.done:
+		return err
+	case <-q.done:
+		return ErrClientClosed
+	}
+}
+
+// priority reports whether iso jumps the send queue: Priority when set,
+// otherwise network management messages (x8xx).
+func (c *ISOClient) priority(iso ISO8583Object) bool {
+	if c.Priority != nil {
+		return c.Priority(iso)
+	}
+	mti := iso.GetMTI()
+	return len(mti) == 4 && mti[1] == '8'
+}
+
+// write sends message on conn, through the send queue of the connection
+// when SendQueue is set.
+func (c *ISOClient) write(conn net.Conn, queue *sendQueue, iso ISO8583Object, message []byte) error {
+	if queue != nil {
+		return queue.write(message, c.priority(iso))
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.LengthHeader.writeFrame(conn, message)
+}
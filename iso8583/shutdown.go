@@ -0,0 +1,113 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// ErrEngineClosed is returned by Run after Stop or Shutdown.
+var ErrEngineClosed = errors.New("iso8583 engine closed")
+
+// Shutdown stops accepting new connections and waits for in-flight handlers
+// to finish. If ctx expires first, the remaining connections are closed and
+// ctx.Err() is returned.
+func (t *TCPIso8583Engine) Shutdown(ctx context.Context) error {
+	t.inShutdown.Store(true)
+	t.closeListeners()
+	t.interruptIdleReads()
+
+	if err := t.waitHandlers(ctx); err != nil {
+		t.closeConns()
+		return err
+	}
+	return nil
+}
+
+// Stop stops accepting new connections, closes every active connection and
+// waits for the handler goroutines to return or ctx to expire.
+func (t *TCPIso8583Engine) Stop(ctx context.Context) error {
+	t.inShutdown.Store(true)
+	t.closeListeners()
+	t.closeConns()
+
+	return t.waitHandlers(ctx)
+}
+
+func (t *TCPIso8583Engine) waitHandlers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.handlerWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *TCPIso8583Engine) trackListener(listener net.Listener, add bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listeners == nil {
+		t.listeners = make(map[net.Listener]struct{})
+	}
+	if add {
+		if t.inShutdown.Load() {
+			return false
+		}
+		t.listeners[listener] = struct{}{}
+	} else {
+		delete(t.listeners, listener)
+	}
+	return true
+}
+
+func (t *TCPIso8583Engine) trackConn(c net.Conn, add bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.activeConns == nil {
+		t.activeConns = make(map[net.Conn]struct{})
+	}
+	if add {
+		if t.inShutdown.Load() {
+			return false
+		}
+		t.activeConns[c] = struct{}{}
+		t.handlerWG.Add(1)
+	} else if _, ok := t.activeConns[c]; ok {
+		delete(t.activeConns, c)
+		t.handlerWG.Done()
+	}
+	return true
+}
+
+func (t *TCPIso8583Engine) closeListeners() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for listener := range t.listeners {
+		_ = listener.Close()
+	}
+}
+
+// interruptIdleReads unblocks keep-alive connections waiting for their next
+// message; handlers already running still get to write their responses.
+func (t *TCPIso8583Engine) interruptIdleReads() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for c := range t.activeConns {
+		_ = c.SetReadDeadline(time.Now())
+	}
+}
+
+func (t *TCPIso8583Engine) closeConns() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for c := range t.activeConns {
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,376 @@
+// Package simulator answers ISO 8583 requests with canned responses, for
+// certification and integration testing against a host that is not there
+// yet. Rules match requests on field values and describe the response with
+// templated fields:
+//
+//	rules:
+//	  - name: declined card
+//	    match: {mti: "0200", 2: "4000000000000002"}
+//	    response: {39: "05"}
+//	  - name: purchase
+//	    match: {mti: "0200", 3: "00*"}
+//	    response: {38: "{{random 6}}", 39: "00"}
+//	  - name: echo
+//	    match: {mti: "0800"}
+//	    response: {39: "00"}
+//
+// The first matching rule answers. A Simulator is used as a library through
+// Respond or served by an engine through Handler:
+//
+//	sim, err := simulator.Load("simulator.yml")
+//	...
+//	engine.AddDefaultHandler(sim.Handler())
+package simulator
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoMatch is returned by Respond when no rule matches the request.
+var ErrNoMatch = errors.New("no simulator rule matches the request")
+
+// Rule is one request matcher and its canned response. Field keys are field
+// numbers or "mti".
+type Rule struct {
+	Name string `yaml:"name"`
+	// Match lists the values the request fields must have. A value ending
+	// in "*" matches by prefix, "*" alone any present value and "" an
+	// absent field. An empty Match matches every request.
+	Match map[string]string `yaml:"match"`
+	// Response sets response fields from templates, see Simulator. The
+	// response starts as iso8583.NewResponseFrom the request, so the MTI
+	// and the echoed fields are already in place.
+	Response map[string]string `yaml:"response"`
+	// Echo replaces iso8583.DefaultEchoFields as the fields copied from the
+	// request.
+	Echo []int `yaml:"echo"`
+	// Delay holds the response back, e.g. to test client timeouts.
+	Delay time.Duration `yaml:"delay"`
+	// Drop sends no response at all.
+	Drop bool `yaml:"drop"`
+}
+
+// Simulator answers requests with the first matching Rule. Response
+// templates may use:
+//
+//	{{stan}}        DE 11 of the request
+//	{{rrn}}         DE 37 of the request
+//	{{field N}}     field N of the request
+//	{{now}}         current UTC time as MMDDhhmmss (DE 7)
+//	{{date}}        current local date as MMDD (DE 13)
+//	{{time}}        current local time as hhmmss (DE 12)
+//	{{random N}}    N random digits, e.g. an authorization code
+//	{{seq N}}       a counter, zero filled to N digits
+//
+// Funcs adds more placeholders, called with the request and the argument
+// after the name. Rules are checked for unknown placeholders when added, so
+// set Funcs before Add or LoadFile when the rules use them.
+type Simulator struct {
+	Funcs map[string]func(req iso8583.ISO8583Object, arg string) (string, error)
+	// Logger receives the responses Handler fails to build. Defaults to
+	// slog.Default.
+	Logger *slog.Logger
+
+	mu    sync.RWMutex
+	rules []Rule
+	seq   atomic.Uint64
+}
+
+// config is the layout of a simulator file.
+type config struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// New creates a simulator answering with rules, in order.
+func New(rules ...Rule) *Simulator {
+	return &Simulator{rules: rules}
+}
+
+// Load reads the rules from the YAML file at path. Only the built-in
+// placeholders are known; use LoadFile for rules relying on Funcs.
+func Load(path string) (*Simulator, error) {
+	s := New()
+	if err := s.LoadFile(path); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadFile appends the rules of the YAML file at path. Nothing is added
+// when a rule is invalid.
+func (s *Simulator) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for i, rule := range cfg.Rules {
+		if err := s.validate(rule); err != nil {
+			return fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, cfg.Rules...)
+	return nil
+}
+
+// Add appends rule, matched after the rules already there.
+func (s *Simulator) Add(rule Rule) error {
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule)
+	return nil
+}
+
+// validate checks the field keys and response placeholders of rule.
+func (s *Simulator) validate(rule Rule) error {
+	for _, fields := range []map[string]string{rule.Match, rule.Response} {
+		for key := range fields {
+			if _, err := fieldKey(key); err != nil {
+				return err
+			}
+		}
+	}
+	for key, tmpl := range rule.Response {
+		for _, sub := range placeholder.FindAllStringSubmatch(tmpl, -1) {
+			if err := s.checkPlaceholder(sub[1], sub[2]); err != nil {
+				return fmt.Errorf("field %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPlaceholder reports an unknown placeholder or an invalid argument.
+func (s *Simulator) checkPlaceholder(name, arg string) error {
+	if _, ok := s.Funcs[name]; ok {
+		return nil
+	}
+	switch name {
+	case "stan", "rrn", "now", "date", "time":
+		return nil
+	case "field":
+		if _, err := strconv.Atoi(arg); err != nil {
+			return fmt.Errorf("{{field %s}}: invalid field number", arg)
+		}
+		return nil
+	case "random", "seq":
+		_, err := width(name, arg)
+		return err
+	}
+	return fmt.Errorf("unknown placeholder {{%s}}", name)
+}
+
+// fieldKey returns the field number of key, 0 for the MTI.
+func fieldKey(key string) (int, error) {
+	if strings.EqualFold(key, "mti") {
+		return 0, nil
+	}
+	field, err := strconv.Atoi(key)
+	if err != nil || field < 2 || field > 192 {
+		return 0, fmt.Errorf("invalid field %q", key)
+	}
+	return field, nil
+}
+
+func get(iso iso8583.ISO8583Object, field int) (string, bool) {
+	if field == 0 {
+		return iso.GetMTI(), true
+	}
+	return iso.GetField(field), iso.HasField(field)
+}
+
+// matches reports whether req satisfies every Match entry of r.
+func (r Rule) matches(req iso8583.ISO8583Object) bool {
+	for key, want := range r.Match {
+		field, _ := fieldKey(key)
+		got, ok := get(req, field)
+		switch {
+		case want == "":
+			if ok {
+				return false
+			}
+		case strings.HasSuffix(want, "*"):
+			if !ok || !strings.HasPrefix(got, strings.TrimSuffix(want, "*")) {
+				return false
+			}
+		case !ok || got != want:
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns the first rule matching req.
+func (s *Simulator) Match(req iso8583.ISO8583Object) (Rule, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rule := range s.rules {
+		if rule.matches(req) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Respond builds the response of the first rule matching req. It returns
+// nil without error for a Drop rule and ErrNoMatch when no rule matches.
+// Delay is left to the caller; Handler applies it.
+func (s *Simulator) Respond(req iso8583.ISO8583Object) (iso8583.ISO8583Object, error) {
+	rule, ok := s.Match(req)
+	if !ok {
+		return nil, ErrNoMatch
+	}
+	return s.respond(rule, req)
+}
+
+func (s *Simulator) respond(rule Rule, req iso8583.ISO8583Object) (iso8583.ISO8583Object, error) {
+	if rule.Drop {
+		return nil, nil
+	}
+	resp := iso8583.NewResponseFrom(req, rule.Echo...)
+	for key, tmpl := range rule.Response {
+		value, err := s.expand(tmpl, req)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q field %s: %w", rule.Name, key, err)
+		}
+		field, _ := fieldKey(key)
+		if field == 0 {
+			resp.SetMTI(value)
+		} else {
+			resp.SetField(field, value)
+		}
+	}
+	return resp, nil
+}
+
+// Handler serves the simulator from an engine. Requests no rule matches
+// and Drop rules get no response.
+func (s *Simulator) Handler() iso8583.TcpHandler {
+	return func(ctx context.Context, w iso8583.ResponseWriter, req iso8583.ISO8583Object) {
+		w.Discard()
+		rule, ok := s.Match(req)
+		if !ok {
+			return
+		}
+		if rule.Delay > 0 {
+			select {
+			case <-time.After(rule.Delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+		resp, err := s.respond(rule, req)
+		if err != nil {
+			s.log().Error("simulator response failed", "rule", rule.Name, "mti", req.GetMTI(), "err", err)
+			return
+		}
+		if resp == nil {
+			return
+		}
+		_ = w.Write(resp)
+	}
+}
+
+func (s *Simulator) log() *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return slog.Default()
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*(\w+)(?:\s+([^}]*?))?\s*\}\}`)
+
+// expand fills the placeholders of tmpl from req.
+func (s *Simulator) expand(tmpl string, req iso8583.ISO8583Object) (string, error) {
+	var firstErr error
+	out := placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		sub := placeholder.FindStringSubmatch(m)
+		value, err := s.value(sub[1], sub[2], req)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return value
+	})
+	return out, firstErr
+}
+
+func (s *Simulator) value(name, arg string, req iso8583.ISO8583Object) (string, error) {
+	if fn, ok := s.Funcs[name]; ok {
+		return fn(req, arg)
+	}
+	now := time.Now()
+	switch name {
+	case "stan":
+		return req.GetField(11), nil
+	case "rrn":
+		return req.GetField(37), nil
+	case "field":
+		field, err := strconv.Atoi(arg)
+		if err != nil {
+			return "", fmt.Errorf("{{field %s}}: invalid field number", arg)
+		}
+		return req.GetField(field), nil
+	case "now":
+		return now.UTC().Format("0102150405"), nil
+	case "date":
+		return now.Format("0102"), nil
+	case "time":
+		return now.Format("150405"), nil
+	case "random":
+		n, err := width(name, arg)
+		if err != nil {
+			return "", err
+		}
+		return randomDigits(n)
+	case "seq":
+		n, err := width(name, arg)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%0*d", n, s.seq.Add(1)), nil
+	}
+	return "", fmt.Errorf("unknown placeholder {{%s}}", name)
+}
+
+func width(name, arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 || n > 64 {
+		return 0, fmt.Errorf("{{%s %s}}: invalid width", name, arg)
+	}
+	return n, nil
+}
+
+func randomDigits(n int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + d.Int64()))
+	}
+	return sb.String(), nil
+}
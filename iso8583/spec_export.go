@@ -0,0 +1,157 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fields returns the field definitions of the active spec by field number,
+// e.g. to display or diff the spec a running engine uses. The result is a
+// copy; changing it does not change the spec.
+func (pk *Packager) Fields() map[int]FieldConfig {
+	pk = pk.active()
+	fields := make(map[int]FieldConfig, len(pk.fields))
+	for index, f := range pk.fields {
+		f.SubFields = maps.Clone(f.SubFields)
+		fields[index] = f
+	}
+	return fields
+}
+
+// ExportYAML returns the active spec in the YAML format LoadSpec reads:
+// options first, then the fields by number, defaults left out.
+func (pk *Packager) ExportYAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(pk); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportJSON returns the active spec in the JSON format LoadSpecJSON reads.
+func (pk *Packager) ExportJSON() ([]byte, error) {
+	return json.MarshalIndent(pk, "", "  ")
+}
+
+// specEntry is one top-level spec entry, an option or a field.
+type specEntry struct {
+	key   string
+	value any
+}
+
+// exportField is FieldConfig without the defaults decodeEntry fills in.
+type exportField struct {
+	ContentType    string              `yaml:"ContentType" json:"ContentType"`
+	Label          string              `yaml:"Label,omitempty" json:"Label,omitempty"`
+	LenType        string              `yaml:"LenType" json:"LenType"`
+	MaxLen         int                 `yaml:"MaxLen" json:"MaxLen"`
+	Encoding       string              `yaml:"Encoding,omitempty" json:"Encoding,omitempty"`
+	Pad            string              `yaml:"Pad,omitempty" json:"Pad,omitempty"`
+	PadChar        string              `yaml:"PadChar,omitempty" json:"PadChar,omitempty"`
+	SubFieldFormat string              `yaml:"SubFieldFormat,omitempty" json:"SubFieldFormat,omitempty"`
+	TagLen         int                 `yaml:"TagLen,omitempty" json:"TagLen,omitempty"`
+	SubFields      map[int]exportField `yaml:"SubFields,omitempty" json:"SubFields,omitempty"`
+	Codec          string              `yaml:"Codec,omitempty" json:"Codec,omitempty"`
+}
+
+func newExportField(f FieldConfig) exportField {
+	e := exportField{
+		ContentType:    f.ContentType,
+		Label:          f.Label,
+		LenType:        f.LenType,
+		MaxLen:         f.MaxLen,
+		Encoding:       f.Encoding,
+		Pad:            f.Pad,
+		PadChar:        f.PadChar,
+		SubFieldFormat: f.SubFieldFormat,
+		TagLen:         f.TagLen,
+		Codec:          f.Codec,
+	}
+	if e.Encoding == EncodingASCII {
+		e.Encoding = ""
+	}
+	if e.SubFieldFormat == SubFieldPositional && len(f.SubFields) == 0 {
+		e.SubFieldFormat = ""
+	}
+	if len(f.SubFields) > 0 {
+		e.SubFields = make(map[int]exportField, len(f.SubFields))
+		for sub, sf := range f.SubFields {
+			e.SubFields[sub] = newExportField(sf)
+		}
+	}
+	return e
+}
+
+// entries lists the spec of pk in export order.
+func (pk *Packager) entries() []specEntry {
+	pk = pk.active()
+	entries := []specEntry{{"BitmapEncoding", pk.BitmapEncoding}}
+	add := func(key string, value any, set bool) {
+		if set {
+			entries = append(entries, specEntry{key, value})
+		}
+	}
+	add("TruncateOverLength", pk.TruncateOverLength, pk.TruncateOverLength)
+	add("Lenient", pk.Lenient, pk.Lenient)
+	add("Version", pk.Version, pk.Version != "")
+	add("TimeZone", pk.TimeZone, pk.TimeZone != "")
+	add("ValidatePAN", pk.ValidatePAN, pk.ValidatePAN)
+	add("Header", pk.Header, pk.Header != FrameConfig{})
+	add("Trailer", pk.Trailer, pk.Trailer != FrameConfig{})
+	add("Codecs", pk.Codecs, len(pk.Codecs) > 0)
+	add("Profiles", pk.Profiles, len(pk.Profiles) > 0)
+
+	indexes := make([]int, 0, len(pk.fields))
+	for index := range pk.fields {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		entries = append(entries, specEntry{strconv.Itoa(index), newExportField(pk.fields[index])})
+	}
+	return entries
+}
+
+// MarshalYAML implements yaml.Marshaler, keeping the entry order.
+func (pk *Packager) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, e := range pk.entries() {
+		var value yaml.Node
+		if err := value.Encode(e.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: e.key}, &value)
+	}
+	return node, nil
+}
+
+// MarshalJSON implements json.Marshaler, keeping the entry order.
+func (pk *Packager) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range pk.entries() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.key)
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
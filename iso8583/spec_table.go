@@ -0,0 +1,139 @@
+package iso8583
+
+import "fmt"
+
+// fieldSpec is a FieldConfig prepared at load time so parsing and composing
+// do not look up maps or compare LenType, ContentType and Encoding strings
+// for every field of every message.
+type fieldSpec struct {
+	FieldConfig
+
+	// digits is the length prefix size: 0 for fixed fields, 2 for LLVAR
+	// and 3 for LLLVAR.
+	digits int
+	// check validates one value byte; nil accepts anything.
+	check  func(c byte) bool
+	hex    bool
+	ebcdic bool
+	binary bool
+}
+
+// maxField is the highest field number a spec can define (tertiary bitmap).
+const maxField = 192
+
+// compile builds the field table from the field map. It runs once the spec
+// is validated, so every LenType is known.
+func (pk *Packager) compile() {
+	pk.table = make([]*fieldSpec, maxField+1)
+	for index, f := range pk.fields {
+		fs := &fieldSpec{
+			FieldConfig: f,
+			hex:         f.Encoding == EncodingHex,
+			ebcdic:      f.Encoding == EncodingEBCDIC,
+			binary:      f.ContentType == "b",
+		}
+		// Binary menerima semua byte, tidak perlu dicek
+		if !fs.binary {
+			fs.check = contentTypeCheckers[f.ContentType]
+		}
+		switch f.LenType {
+		case "llvar":
+			fs.digits = 2
+		case "lllvar":
+			fs.digits = 3
+		}
+		pk.table[index] = fs
+	}
+}
+
+// spec returns the prepared spec of field index.
+func (pk *Packager) spec(index int) (*fieldSpec, bool) {
+	if index < 0 || index >= len(pk.table) {
+		return nil, false
+	}
+	fs := pk.table[index]
+	return fs, fs != nil
+}
+
+// readLength decodes a length prefix, accepting digits only, without
+// converting it to a string first.
+func (fs *fieldSpec) readLength(prefix []byte) (int, error) {
+	length := 0
+	for _, c := range prefix {
+		if fs.ebcdic {
+			c = ebcdicToASCII[c]
+		}
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid length prefix %q", fs.decodeText(prefix))
+		}
+		length = length*10 + int(c-'0')
+	}
+	return length, nil
+}
+
+// checkValue is checkContentType with the checker resolved at load time.
+func (fs *fieldSpec) checkValue(value string) error {
+	if fs.check == nil {
+		return nil
+	}
+	for i := 0; i < len(value); i++ {
+		if !fs.check(value[i]) {
+			return fmt.Errorf("invalid character %q at position %d for content type %q", value[i], i, fs.ContentType)
+		}
+	}
+	return nil
+}
+
+// wireSize returns how many wire bytes carry n value bytes.
+func (fs *fieldSpec) wireSize(n int) int {
+	if fs.hex {
+		return n * 2
+	}
+	return n
+}
+
+// appendText appends the wire bytes of the ASCII text s to dst.
+func (fs *fieldSpec) appendText(dst []byte, s string) []byte {
+	if fs.ebcdic {
+		for i := 0; i < len(s); i++ {
+			dst = append(dst, asciiToEBCDIC[s[i]])
+		}
+		return dst
+	}
+	return append(dst, s...)
+}
+
+// appendLength appends the LLVAR/LLLVAR prefix for length n, encoded like
+// the field text.
+func (fs *fieldSpec) appendLength(dst []byte, n int) []byte {
+	var buf [3]byte
+	for i := fs.digits - 1; i >= 0; i-- {
+		buf[i] = byte('0' + n%10)
+		n /= 10
+	}
+	return fs.appendText(dst, string(buf[:fs.digits]))
+}
+
+// appendValue appends the wire bytes of the stored field value to dst.
+func (fs *fieldSpec) appendValue(dst []byte, v string) []byte {
+	switch {
+	case fs.hex:
+		return appendHexUpper(dst, v)
+	case fs.binary:
+		return append(dst, v...)
+	default:
+		return fs.appendText(dst, v)
+	}
+}
+
+// value converts the wire bytes of the field data to the stored value.
+func (fs *fieldSpec) value(b []byte) (string, error) {
+	switch {
+	case fs.hex:
+		return fs.decodeValue(b)
+	case fs.binary || !fs.ebcdic:
+		return string(b), nil
+	default:
+		return fs.decodeText(b), nil
+	}
+}
@@ -0,0 +1,137 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SpecError lists every problem found in a spec. Problems tied to a data
+// element are *FieldError values.
+type SpecError struct {
+	Problems []error
+}
+
+func (e *SpecError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		msgs = append(msgs, problem.Error())
+	}
+	return "invalid spec: " + strings.Join(msgs, "; ")
+}
+
+// validate checks the decoded spec and reports all problems at once.
+func (pk *Packager) validate() error {
+	serr := &SpecError{}
+
+	switch pk.BitmapEncoding {
+	case BitmapHex, BitmapBinary:
+	default:
+		serr.Problems = append(serr.Problems, fmt.Errorf("unknown BitmapEncoding %q", pk.BitmapEncoding))
+	}
+
+	if _, ok := versionDigits[pk.Version]; pk.Version != "" && !ok {
+		serr.Problems = append(serr.Problems, fmt.Errorf("unknown Version %q", pk.Version))
+	}
+
+	if pk.TimeZone != "" {
+		location, err := time.LoadLocation(pk.TimeZone)
+		if err != nil {
+			serr.Problems = append(serr.Problems, fmt.Errorf("TimeZone: %w", err))
+		}
+		pk.location = location
+	}
+
+	for _, err := range pk.Header.check(false) {
+		serr.Problems = append(serr.Problems, fmt.Errorf("Header: %w", err))
+	}
+	for _, err := range pk.Trailer.check(true) {
+		serr.Problems = append(serr.Problems, fmt.Errorf("Trailer: %w", err))
+	}
+
+	for _, index := range []int{0, 1} {
+		if _, ok := pk.fields[index]; !ok {
+			serr.Problems = append(serr.Problems, &FieldError{Field: index, Err: errors.New("configuration missing")})
+		}
+	}
+
+	indexes := make([]int, 0, len(pk.fields))
+	for index := range pk.fields {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		if index < 0 || index > 192 {
+			serr.Problems = append(serr.Problems, &FieldError{Field: index, Err: errors.New("field number out of range 0-192")})
+			continue
+		}
+		for _, err := range checkFieldConfig(pk.fields[index], index > 1) {
+			serr.Problems = append(serr.Problems, &FieldError{Field: index, Err: err})
+		}
+	}
+
+	if len(serr.Problems) > 0 {
+		return serr
+	}
+	return nil
+}
+
+// checkFieldConfig returns every problem in a single field definition.
+// Subfield definitions are checked the same way.
+func checkFieldConfig(f FieldConfig, checkSubFields bool) []error {
+	var problems []error
+
+	switch f.LenType {
+	case "fixed", "llvar", "lllvar":
+	default:
+		problems = append(problems, fmt.Errorf("unknown LenType %q", f.LenType))
+	}
+
+	if f.MaxLen <= 0 {
+		problems = append(problems, fmt.Errorf("MaxLen must be positive, got %d", f.MaxLen))
+	}
+	if f.LenType == "llvar" && f.MaxLen > 99 {
+		problems = append(problems, fmt.Errorf("MaxLen %d does not fit a 2-digit LLVAR prefix", f.MaxLen))
+	}
+	if f.LenType == "lllvar" && f.MaxLen > 999 {
+		problems = append(problems, fmt.Errorf("MaxLen %d does not fit a 3-digit LLLVAR prefix", f.MaxLen))
+	}
+
+	if _, ok := contentTypeCheckers[f.ContentType]; !ok && f.ContentType != "" {
+		problems = append(problems, fmt.Errorf("unknown ContentType %q", f.ContentType))
+	}
+
+	switch f.Encoding {
+	case "", EncodingASCII, EncodingEBCDIC, EncodingHex:
+	default:
+		problems = append(problems, fmt.Errorf("unknown Encoding %q", f.Encoding))
+	}
+
+	switch f.Pad {
+	case "", PadLeft, PadRight, PadNone:
+	default:
+		problems = append(problems, fmt.Errorf("unknown Pad %q", f.Pad))
+	}
+	if len(f.PadChar) > 1 {
+		problems = append(problems, fmt.Errorf("PadChar %q must be a single character", f.PadChar))
+	}
+
+	switch f.SubFieldFormat {
+	case "", SubFieldPositional, SubFieldTagged:
+	default:
+		problems = append(problems, fmt.Errorf("unknown SubFieldFormat %q", f.SubFieldFormat))
+	}
+
+	if checkSubFields {
+		for _, sub := range sortedSubFields(f.SubFields) {
+			for _, err := range checkFieldConfig(f.SubFields[sub], false) {
+				problems = append(problems, fmt.Errorf("subfield %d: %w", sub, err))
+			}
+		}
+	}
+
+	return problems
+}
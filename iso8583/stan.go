@@ -0,0 +1,122 @@
+package iso8583
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSTAN is the highest DE 11 value before the counter rolls back to 1.
+const maxSTAN = 999999
+
+// CounterStore persists the last issued counter value so a generator can
+// resume after a restart.
+type CounterStore interface {
+	Load() (uint64, error)
+	Save(value uint64) error
+}
+
+// FileCounterStore keeps the counter as a decimal number in a file. A
+// missing file loads as zero.
+type FileCounterStore struct {
+	Path string
+}
+
+// Load implements CounterStore.
+func (s FileCounterStore) Load() (uint64, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// Save implements CounterStore. The value is written to a temporary file
+// and renamed over Path so a crash never leaves a half written counter.
+func (s FileCounterStore) Save(value uint64) error {
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(value, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// STANGenerator issues DE 11 system trace audit numbers, rolling from
+// 999999 back to 000001. It is safe for concurrent use.
+type STANGenerator struct {
+	mu    sync.Mutex
+	last  uint64
+	store CounterStore
+}
+
+// NewSTANGenerator creates a generator that resumes from the value in store.
+// A nil store keeps the counter in memory only.
+func NewSTANGenerator(store CounterStore) (*STANGenerator, error) {
+	g := &STANGenerator{store: store}
+	if store != nil {
+		last, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		g.last = last % (maxSTAN + 1)
+	}
+	return g, nil
+}
+
+// Next returns the next STAN as 6 digits. The new value is saved before it
+// is returned; if saving fails the counter is not advanced.
+func (g *STANGenerator) Next() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	next := g.last + 1
+	if next > maxSTAN {
+		next = 1
+	}
+	if g.store != nil {
+		if err := g.store.Save(next); err != nil {
+			return "", err
+		}
+	}
+	g.last = next
+	return fmt.Sprintf("%06d", next), nil
+}
+
+// RRN builds a 12 digit DE 37 retrieval reference number in the common
+// YDDDHHNNNNNN layout: last digit of the year, Julian day, hour and the
+// 6 digit STAN.
+func RRN(t time.Time, stan string) string {
+	if len(stan) > 6 {
+		stan = stan[len(stan)-6:]
+	}
+	stan = strings.Repeat("0", 6-len(stan)) + stan
+	return fmt.Sprintf("%d%03d%02d%s", t.Year()%10, t.YearDay(), t.Hour(), stan)
+}
+
+// RRNGenerator issues DE 11 and matching DE 37 values from one STAN counter.
+type RRNGenerator struct {
+	STAN *STANGenerator
+	// Now returns the time used for the RRN date part. Defaults to
+	// time.Now.
+	Now func() time.Time
+}
+
+// Next returns a new STAN and the RRN built from it.
+func (g *RRNGenerator) Next() (stan, rrn string, err error) {
+	stan, err = g.STAN.Next()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	return stan, RRN(now(), stan), nil
+}
@@ -0,0 +1,211 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	// SubFieldPositional lays subfields out one after another in subfield
+	// number order, each framed by its own LenType.
+	SubFieldPositional = "positional"
+	// SubFieldTagged prefixes each subfield with its number as a TagLen-digit
+	// tag; subfields may then appear in any order or be left out.
+	SubFieldTagged = "tagged"
+)
+
+const defaultTagLen = 2
+
+// GetSubField implements ISO8583Object. It decodes the composite field index
+// using the SubFields layout declared in the spec and returns subfield sub,
+// which must be declared there.
+func (p *isoObject) GetSubField(index, sub int) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if err := p.checkSubField(index, sub); err != nil {
+		return "", err
+	}
+	subFields, err := p.decodeSubFields(index)
+	if err != nil {
+		return "", err
+	}
+	return subFields[sub], nil
+}
+
+// SetSubField implements ISO8583Object. The other subfields already present
+// in field index are kept. Subfields not declared in the spec are rejected,
+// as they could not be composed.
+func (p *isoObject) SetSubField(index, sub int, val any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.checkSubField(index, sub); err != nil {
+		return err
+	}
+	subFields, err := p.decodeSubFields(index)
+	if err != nil {
+		return err
+	}
+	subFields[sub] = fmt.Sprint(val)
+
+	value, err := p.encodeSubFields(index, subFields)
+	if err != nil {
+		return err
+	}
+	p.isoElement[index] = value
+	return nil
+}
+
+func (p *isoObject) subFieldConfig(index int) (FieldConfig, error) {
+	fieldConfig, ok := p.packager.fields[index]
+	if !ok || len(fieldConfig.SubFields) == 0 {
+		return fieldConfig, &FieldError{Field: index, Err: errors.New("no subfields declared in spec")}
+	}
+	return fieldConfig, nil
+}
+
+// checkSubField fails unless the spec declares subfield sub of field index.
+func (p *isoObject) checkSubField(index, sub int) error {
+	fieldConfig, err := p.subFieldConfig(index)
+	if err != nil {
+		return err
+	}
+	if _, ok := fieldConfig.SubFields[sub]; !ok {
+		return &FieldError{Field: index, Err: fmt.Errorf("subfield %d not declared in spec", sub)}
+	}
+	return nil
+}
+
+func (p *isoObject) decodeSubFields(index int) (map[int]string, error) {
+	fieldConfig, err := p.subFieldConfig(index)
+	if err != nil {
+		return nil, err
+	}
+
+	value := []byte(p.isoElement[index])
+	subFields := make(map[int]string)
+	pos := 0
+
+	if fieldConfig.SubFieldFormat == SubFieldTagged {
+		tagLen := fieldConfig.tagLen()
+		for pos < len(value) {
+			if pos+tagLen > len(value) {
+				return nil, &FieldError{Field: index, Err: errMessageTruncated}
+			}
+			sub, err := strconv.Atoi(string(value[pos : pos+tagLen]))
+			if err != nil {
+				return nil, &FieldError{Field: index, Err: fmt.Errorf("invalid subfield tag %q", value[pos:pos+tagLen])}
+			}
+			subConfig, ok := fieldConfig.SubFields[sub]
+			if !ok {
+				return nil, &FieldError{Field: index, Err: fmt.Errorf("subfield %d configuration missing", sub)}
+			}
+			pos += tagLen
+
+			subValue, next, err := readSubField(value, pos, subConfig)
+			if err != nil {
+				return nil, &FieldError{Field: index, Err: fmt.Errorf("subfield %d: %w", sub, err)}
+			}
+			subFields[sub] = subValue
+			pos = next
+		}
+		return subFields, nil
+	}
+
+	for _, sub := range sortedSubFields(fieldConfig.SubFields) {
+		if pos >= len(value) {
+			break
+		}
+		subValue, next, err := readSubField(value, pos, fieldConfig.SubFields[sub])
+		if err != nil {
+			return nil, &FieldError{Field: index, Err: fmt.Errorf("subfield %d: %w", sub, err)}
+		}
+		subFields[sub] = subValue
+		pos = next
+	}
+	return subFields, nil
+}
+
+func (p *isoObject) encodeSubFields(index int, subFields map[int]string) (string, error) {
+	fieldConfig, err := p.subFieldConfig(index)
+	if err != nil {
+		return "", err
+	}
+
+	tagged := fieldConfig.SubFieldFormat == SubFieldTagged
+	var out []byte
+	for _, sub := range sortedSubFields(fieldConfig.SubFields) {
+		subValue, ok := subFields[sub]
+		if !ok && tagged {
+			continue
+		}
+		subConfig := fieldConfig.SubFields[sub]
+
+		if tagged {
+			out = fmt.Appendf(out, "%0*d", fieldConfig.tagLen(), sub)
+		}
+		switch subConfig.LenType {
+		case "llvar", "lllvar":
+			if len(subValue) > subConfig.MaxLen {
+				return "", &FieldError{Field: index, Err: fmt.Errorf("subfield %d exceeds MaxLen %d", sub, subConfig.MaxLen)}
+			}
+			digits := 2
+			if subConfig.LenType == "lllvar" {
+				digits = 3
+			}
+			out = fmt.Appendf(out, "%0*d", digits, len(subValue))
+			out = append(out, subValue...)
+		default:
+			padded, err := p.padValue(subValue, subConfig)
+			if err != nil {
+				return "", &FieldError{Field: index, Err: fmt.Errorf("subfield %d: %w", sub, err)}
+			}
+			out = append(out, padded...)
+		}
+	}
+	return string(out), nil
+}
+
+func readSubField(value []byte, pos int, subConfig FieldConfig) (string, int, error) {
+	length := subConfig.MaxLen
+	switch subConfig.LenType {
+	case "llvar", "lllvar":
+		digits := 2
+		if subConfig.LenType == "lllvar" {
+			digits = 3
+		}
+		if pos+digits > len(value) {
+			return "", pos, errMessageTruncated
+		}
+		var err error
+		length, err = parseLength(string(value[pos : pos+digits]))
+		if err != nil {
+			return "", pos, err
+		}
+		pos += digits
+	}
+
+	if pos+length > len(value) {
+		return "", pos, errMessageTruncated
+	}
+	return string(value[pos : pos+length]), pos + length, nil
+}
+
+func sortedSubFields(subFields map[int]FieldConfig) []int {
+	keys := make([]int, 0, len(subFields))
+	for k := range subFields {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func (f FieldConfig) tagLen() int {
+	if f.TagLen > 0 {
+		return f.TagLen
+	}
+	return defaultTagLen
+}
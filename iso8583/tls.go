@@ -0,0 +1,23 @@
+package iso8583
+
+import "crypto/tls"
+
+// serverTLSConfig chains VerifyClient after any VerifyConnection already set
+// on config.
+func (t *TCPIso8583Engine) serverTLSConfig(config *tls.Config) *tls.Config {
+	if t.VerifyClient == nil {
+		return config
+	}
+
+	config = config.Clone()
+	verifyConnection := config.VerifyConnection
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if verifyConnection != nil {
+			if err := verifyConnection(state); err != nil {
+				return err
+			}
+		}
+		return t.VerifyClient(state)
+	}
+	return config
+}
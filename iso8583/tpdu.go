@@ -0,0 +1,91 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// ErrTPDU is returned for a header that is not a 5 byte TPDU.
+var ErrTPDU = errors.New("invalid TPDU")
+
+// TPDUHeader is the FrameConfig of a TPDU header, with the usual 60 ID and
+// zero addresses as default:
+//
+//	packager.Header = iso8583.TPDUHeader
+var TPDUHeader = FrameConfig{Length: 5, Encoding: EncodingHex, Default: "6000000000"}
+
+// TPDU is the transport protocol data unit POS terminals put before the
+// MTI: an ID (60 for transactions), a 2 byte destination and a 2 byte
+// source address, each kept as hex text, e.g. ID "60", Destination "0001"
+// (the NII of the host) and Source "0000".
+type TPDU struct {
+	ID          string
+	Destination string
+	Source      string
+}
+
+// ParseTPDU parses the 10 hex digit form of a TPDU, as returned by
+// GetHeader for a TPDUHeader.
+func ParseTPDU(s string) (TPDU, error) {
+	if len(s) != 10 {
+		return TPDU{}, ErrTPDU
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return TPDU{}, ErrTPDU
+	}
+	s = strings.ToUpper(s)
+	return TPDU{ID: s[:2], Destination: s[2:6], Source: s[6:]}, nil
+}
+
+// String returns the 10 hex digit form of t, to be set with SetHeader.
+func (t TPDU) String() string {
+	return strings.ToUpper(t.ID + t.Destination + t.Source)
+}
+
+// Bytes returns the 5 wire bytes of t.
+func (t TPDU) Bytes() ([]byte, error) {
+	b, err := hex.DecodeString(t.String())
+	if err != nil || len(b) != 5 {
+		return nil, ErrTPDU
+	}
+	return b, nil
+}
+
+// Reply returns the TPDU of a response to t: source and destination
+// swapped.
+func (t TPDU) Reply() TPDU {
+	return TPDU{ID: t.ID, Destination: t.Source, Source: t.Destination}
+}
+
+// GetTPDU parses the header of iso as a TPDU.
+func GetTPDU(iso ISO8583Object) (TPDU, error) {
+	return ParseTPDU(iso.GetHeader())
+}
+
+// MatchTPDUDestination returns a Route.Match predicate selecting messages
+// whose TPDU is addressed to destination, e.g. to route by NII:
+//
+//	engine.Router().Handle(iso8583.Route{
+//		MTI:     "0200",
+//		Match:   iso8583.MatchTPDUDestination("0001"),
+//		Handler: hostA,
+//	})
+func MatchTPDUDestination(destination string) func(iso ISO8583Object) bool {
+	destination = strings.ToUpper(destination)
+	return func(iso ISO8583Object) bool {
+		tpdu, err := GetTPDU(iso)
+		return err == nil && tpdu.Destination == destination
+	}
+}
+
+// replyTPDU swaps the TPDU addresses of a response still carrying the
+// request TPDU. A response whose handler set its own header is left alone.
+func replyTPDU(response ISO8583Object, requestTPDU string) {
+	if requestTPDU == "" || response.GetHeader() != requestTPDU {
+		return
+	}
+	if tpdu, err := ParseTPDU(requestTPDU); err == nil {
+		response.SetHeader(tpdu.Reply().String())
+	}
+}
@@ -0,0 +1,54 @@
+package iso8583
+
+import "context"
+
+// Tracer creates spans around the engine work on each inbound message: one
+// "iso8583.message" span with "iso8583.parse", "iso8583.handle" and
+// "iso8583.compose" children. The context a handler receives carries the
+// message span, so a Tracer backed by OpenTelemetry propagates the trace to
+// downstream calls made with that context. An OpenTelemetry adapter only
+// needs to wrap trace.Tracer.Start and trace.Span.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced operation.
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// Span attribute keys set on the message span.
+const (
+	AttrMTI          = "iso8583.mti"
+	AttrSTAN         = "iso8583.stan"
+	AttrRRN          = "iso8583.rrn"
+	AttrResponseCode = "iso8583.response_code"
+)
+
+// startSpan starts a span when the engine has a Tracer. The returned span is
+// never nil.
+func (t *TCPIso8583Engine) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if t.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return t.Tracer.Start(ctx, name)
+}
+
+// setMessageAttributes copies the identifying fields of iso onto span.
+func setMessageAttributes(span Span, iso ISO8583Object) {
+	span.SetAttribute(AttrMTI, iso.GetMTI())
+	if stan := iso.GetField(11); stan != "" {
+		span.SetAttribute(AttrSTAN, stan)
+	}
+	if rrn := iso.GetField(37); rrn != "" {
+		span.SetAttribute(AttrRRN, rrn)
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, string) {}
+func (noopSpan) RecordError(error)           {}
+func (noopSpan) End()                        {}
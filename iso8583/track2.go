@@ -0,0 +1,22 @@
+package iso8583
+
+import "github.com/randyardiansyah25/go-iso8583/iso8583/card"
+
+// GetTrack2 parses DE 35 of iso, see card.ParseTrack2.
+func GetTrack2(iso ISO8583Object) (card.Track2, error) {
+	t, err := card.ParseTrack2(iso.GetField(35))
+	if err != nil {
+		return card.Track2{}, &FieldError{Field: 35, Err: err}
+	}
+	return t, nil
+}
+
+// SetTrack2 checks t with card.BuildTrack2 and sets it as DE 35 of iso.
+func SetTrack2(iso ISO8583Object, t card.Track2) error {
+	track, err := card.BuildTrack2(t)
+	if err != nil {
+		return &FieldError{Field: 35, Err: err}
+	}
+	iso.SetField(35, track)
+	return nil
+}
@@ -0,0 +1,256 @@
+package iso8583
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// TxRecord is a transaction kept in a TxStore: the request and the response
+// sent for it.
+type TxRecord struct {
+	Request  ISO8583Object
+	Response ISO8583Object
+	Created  time.Time
+}
+
+// TxStore keeps transactions for ttl, e.g. in memory (MemoryTxStore) or in a
+// database shared by several engine instances. Implementations must be safe
+// for concurrent use.
+type TxStore interface {
+	Put(key string, record TxRecord, ttl time.Duration) error
+	// Get returns the record stored under key; ok is false when there is
+	// none or it expired.
+	Get(key string) (record TxRecord, ok bool, err error)
+	Delete(key string) error
+}
+
+// DefaultTxTTL is how long Transactions keeps a record when TTL is not set.
+const DefaultTxTTL = 24 * time.Hour
+
+// Transactions records the responses the engine sends so reversal and
+// advice handlers can look up the original authorization. Records are found
+// by RRN (DE 37) and by STAN with transmission date and time (DE 11 and 7),
+// the pair DE 90 of a reversal carries.
+//
+//	tx := &iso8583.Transactions{}
+//	engine.Use(tx.Middleware())
+//	engine.AddHandler(func(ctx context.Context, w iso8583.ResponseWriter, iso iso8583.ISO8583Object) {
+//		original, ok, err := tx.Original(iso)
+//		...
+//	}, "0400")
+type Transactions struct {
+	// Store defaults to a MemoryTxStore.
+	Store TxStore
+	// TTL defaults to DefaultTxTTL.
+	TTL time.Duration
+	// Logger receives Middleware store errors. Defaults to slog.Default.
+	Logger *slog.Logger
+
+	once sync.Once
+}
+
+func (t *Transactions) store() TxStore {
+	t.once.Do(func() {
+		if t.Store == nil {
+			t.Store = NewMemoryTxStore()
+		}
+	})
+	return t.Store
+}
+
+func (t *Transactions) ttl() time.Duration {
+	if t.TTL > 0 {
+		return t.TTL
+	}
+	return DefaultTxTTL
+}
+
+// log returns Logger, or slog.Default when none is set.
+func (t *Transactions) log() *slog.Logger {
+	if t.Logger != nil {
+		return t.Logger
+	}
+	return slog.Default()
+}
+
+func rrnKey(rrn string) string {
+	return "rrn|" + rrn
+}
+
+// stanKey pakai 6 digit terakhir STAN, sama seperti isi DE 90
+func stanKey(stan, transmission string) string {
+	return "stan|" + zeroFill(stan, 6) + "|" + transmission
+}
+
+// Record stores request and its response under the RRN and STAN keys of
+// request. Keys whose fields are absent are skipped.
+func (t *Transactions) Record(request, response ISO8583Object) error {
+	record := TxRecord{Request: request, Response: response, Created: time.Now()}
+	store := t.store()
+	if rrn := request.GetField(37); rrn != "" {
+		if err := store.Put(rrnKey(rrn), record, t.ttl()); err != nil {
+			return err
+		}
+	}
+	if stan := request.GetField(11); stan != "" && request.HasField(7) {
+		if err := store.Put(stanKey(stan, request.GetField(7)), record, t.ttl()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ByRRN returns the transaction with retrieval reference number rrn.
+func (t *Transactions) ByRRN(rrn string) (TxRecord, bool, error) {
+	return t.store().Get(rrnKey(rrn))
+}
+
+// BySTAN returns the transaction with STAN stan sent at transmission, the
+// DE 7 value MMDDhhmmss.
+func (t *Transactions) BySTAN(stan, transmission string) (TxRecord, bool, error) {
+	return t.store().Get(stanKey(stan, transmission))
+}
+
+// Original returns the transaction a reversal or advice refers to: the one
+// named by its DE 90, or else the one with the same RRN.
+func (t *Transactions) Original(iso ISO8583Object) (TxRecord, bool, error) {
+	if iso.HasField(90) {
+		if d, err := GetOriginalData(iso); err == nil {
+			record, ok, err := t.BySTAN(d.STAN, d.TransmissionDateTime)
+			if ok || err != nil {
+				return record, ok, err
+			}
+		}
+	}
+	if rrn := iso.GetField(37); rrn != "" {
+		return t.ByRRN(rrn)
+	}
+	return TxRecord{}, false, nil
+}
+
+// Remove deletes the transaction of request, e.g. once it is reversed.
+func (t *Transactions) Remove(request ISO8583Object) error {
+	store := t.store()
+	if rrn := request.GetField(37); rrn != "" {
+		if err := store.Delete(rrnKey(rrn)); err != nil {
+			return err
+		}
+	}
+	if stan := request.GetField(11); stan != "" && request.HasField(7) {
+		return store.Delete(stanKey(stan, request.GetField(7)))
+	}
+	return nil
+}
+
+// Middleware records every authorization and financial request (x1xx and
+// x2xx) the handler answers together with its response: the message it
+// writes, or the request object itself when the engine sends that back.
+// Reversals are not recorded so they do not replace the original under its
+// RRN.
+func (t *Transactions) Middleware() Middleware {
+	return func(next TcpHandler) TcpHandler {
+		return func(ctx context.Context, w ResponseWriter, iso ISO8583Object) {
+			if mti := iso.GetMTI(); !isRequestMTI(mti) || (mti[1] != '1' && mti[1] != '2') {
+				next(ctx, w, iso)
+				return
+			}
+			request := iso.Clone()
+			rw := &txRecorder{ResponseWriter: w}
+			next(ctx, rw, iso)
+
+			response := rw.response
+			if response == nil && !rw.written && !rw.discarded {
+				response = iso
+			}
+			if response == nil {
+				return
+			}
+			if err := t.Record(request, response); err != nil {
+				t.log().Error("record transaction failed", "mti", request.GetMTI(), "stan", request.GetField(11), "err", err)
+			}
+		}
+	}
+}
+
+// txRecorder catches the response a handler writes.
+type txRecorder struct {
+	ResponseWriter
+	response  ISO8583Object
+	written   bool
+	discarded bool
+}
+
+func (r *txRecorder) Write(iso ISO8583Object) error {
+	r.written = true
+	// Advice atau request yang dikirim handler bukan respon
+	if r.response == nil && !isRequestMTI(iso.GetMTI()) {
+		r.response = iso
+	}
+	return r.ResponseWriter.Write(iso)
+}
+
+func (r *txRecorder) WriteRaw(message []byte) error {
+	r.written = true
+	return r.ResponseWriter.WriteRaw(message)
+}
+
+func (r *txRecorder) Discard() {
+	r.discarded = true
+	r.ResponseWriter.Discard()
+}
+
+// MemoryTxStore is a TxStore in process memory. Expired records are purged
+// while storing new ones.
+type MemoryTxStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryTxRecord
+	lastPurge time.Time
+}
+
+type memoryTxRecord struct {
+	record  TxRecord
+	expires time.Time
+}
+
+// NewMemoryTxStore creates an empty MemoryTxStore.
+func NewMemoryTxStore() *MemoryTxStore {
+	return &MemoryTxStore{records: make(map[string]memoryTxRecord)}
+}
+
+// Put implements TxStore.
+func (m *MemoryTxStore) Put(key string, record TxRecord, ttl time.Duration) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastPurge) >= time.Second {
+		for k, r := range m.records {
+			if now.After(r.expires) {
+				delete(m.records, k)
+			}
+		}
+		m.lastPurge = now
+	}
+	m.records[key] = memoryTxRecord{record: record, expires: now.Add(ttl)}
+	return nil
+}
+
+// Get implements TxStore.
+func (m *MemoryTxStore) Get(key string) (TxRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[key]
+	if !ok || time.Now().After(r.expires) {
+		return TxRecord{}, false, nil
+	}
+	return r.record, true, nil
+}
+
+// Delete implements TxStore.
+func (m *MemoryTxStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, key)
+	return nil
+}
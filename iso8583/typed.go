@@ -0,0 +1,201 @@
+package iso8583
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts maps date/time data elements to their Go time layout.
+var timeLayouts = map[int]string{
+	7:  "0102150405", // MMDDhhmmss
+	12: "150405",     // hhmmss
+	13: "0102",       // MMDD
+	14: "0601",       // YYMM
+	15: "0102",
+	16: "0102",
+	17: "0102",
+	73: "060102", // YYMMDD
+}
+
+// GetInt implements ISO8583Object. It parses the field as a decimal integer.
+func (p *isoObject) GetInt(index int) (int64, error) {
+	value := p.GetField(index)
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, &FieldError{Field: index, Err: err}
+	}
+	return n, nil
+}
+
+// GetAmount implements ISO8583Object. It returns the amount in minor units.
+// A leading 'C' (credit) or 'D' (debit) sign, as used by the x+n fee
+// fields, is honored.
+func (p *isoObject) GetAmount(index int) (int64, error) {
+	value := p.GetField(index)
+	sign := int64(1)
+	if len(value) > 0 && (value[0] == 'C' || value[0] == 'D') {
+		if value[0] == 'D' {
+			sign = -1
+		}
+		value = value[1:]
+	}
+
+	if value == "" {
+		return 0, &FieldError{Field: index, Err: fmt.Errorf("amount is empty")}
+	}
+	for i := 0; i < len(value); i++ {
+		if !isNumeric(value[i]) {
+			return 0, &FieldError{Field: index, Err: fmt.Errorf("invalid amount %q", p.GetField(index))}
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &FieldError{Field: index, Err: err}
+	}
+	return sign * n, nil
+}
+
+// SetAmount implements ISO8583Object. minorUnits is zero padded to the
+// field's MaxLen, e.g. 150000 becomes "000000150000" for DE 4.
+func (p *isoObject) SetAmount(index int, minorUnits int64) error {
+	if minorUnits < 0 {
+		return &FieldError{Field: index, Err: fmt.Errorf("negative amount %d", minorUnits)}
+	}
+
+	width := 12
+	if fieldConfig, ok := p.packager.fields[index]; ok && fieldConfig.MaxLen > 0 {
+		width = fieldConfig.MaxLen
+	}
+	value := fmt.Sprintf("%0*d", width, minorUnits)
+	if len(value) > width {
+		return &FieldError{Field: index, Err: fmt.Errorf("amount %d exceeds %d digits", minorUnits, width)}
+	}
+
+	p.SetField(index, value)
+	return nil
+}
+
+// GetTime implements ISO8583Object. It parses the date/time data elements
+// (7, 12, 13, 14, 15, 16, 17, 73), DE 7 in UTC and the others in the spec
+// TimeZone. Fields without a year get the year that puts them nearest to
+// now, so an MMDD of 1231 read on January 1st is last year's; the time-only
+// DE 12 likewise gets yesterday's, today's or tomorrow's date.
+func (p *isoObject) GetTime(index int) (time.Time, error) {
+	layout, ok := p.packager.timeLayout(index)
+	if !ok {
+		return time.Time{}, &FieldError{Field: index, Err: fmt.Errorf("not a date/time field")}
+	}
+
+	location := p.packager.zone(index)
+	t, err := time.ParseInLocation(layout, p.GetField(index), location)
+	if err != nil {
+		return time.Time{}, &FieldError{Field: index, Err: err}
+	}
+	return completeTime(t, layout, time.Now().In(location)), nil
+}
+
+// SetTime implements ISO8583Object. It formats t for date/time field index,
+// converted to UTC for DE 7 and to the spec TimeZone for the others.
+func (p *isoObject) SetTime(index int, t time.Time) error {
+	layout, ok := p.packager.timeLayout(index)
+	if !ok {
+		return &FieldError{Field: index, Err: fmt.Errorf("not a date/time field")}
+	}
+	p.SetField(index, t.In(p.packager.zone(index)).Format(layout))
+	return nil
+}
+
+// SetTransmissionTime implements ISO8583Object. It sets DE 7, the
+// transmission date and time in UTC.
+func (p *isoObject) SetTransmissionTime(t time.Time) error {
+	return p.SetTime(7, t)
+}
+
+// SetLocalTime implements ISO8583Object. It sets the local transaction time
+// DE 12 and, where DE 12 carries no date (1987), the local date DE 13, both
+// in the spec TimeZone.
+func (p *isoObject) SetLocalTime(t time.Time) error {
+	if err := p.SetTime(12, t); err != nil {
+		return err
+	}
+	if layout, _ := p.packager.timeLayout(12); !strings.Contains(layout, "01") {
+		return p.SetTime(13, t)
+	}
+	return nil
+}
+
+// GetLocalTime implements ISO8583Object. It returns the local transaction
+// date and time from DE 12, completed with the date of DE 13 when DE 12
+// carries no date of its own.
+func (p *isoObject) GetLocalTime() (time.Time, error) {
+	layout, _ := p.packager.timeLayout(12)
+	if strings.Contains(layout, "01") || !p.HasField(13) {
+		return p.GetTime(12)
+	}
+
+	location := p.packager.zone(12)
+	layout = timeLayouts[13] + layout
+	t, err := time.ParseInLocation(layout, p.GetField(13)+p.GetField(12), location)
+	if err != nil {
+		return time.Time{}, &FieldError{Field: 12, Err: err}
+	}
+	return completeTime(t, layout, time.Now().In(location)), nil
+}
+
+// timeLayout returns the layout of date/time field index. A 12 digit DE 12
+// is the ISO 8583:1993 local date and time, YYMMDDhhmmss.
+func (pk *Packager) timeLayout(index int) (string, bool) {
+	if index == 12 {
+		if fs, ok := pk.spec(12); ok && fs.MaxLen == 12 {
+			return "060102150405", true
+		}
+	}
+	layout, ok := timeLayouts[index]
+	return layout, ok
+}
+
+// zone returns the location date/time field index is kept in: UTC for DE 7,
+// TimeZone for the others.
+func (pk *Packager) zone(index int) *time.Location {
+	if index == 7 || pk.TimeZone == "" {
+		return time.UTC
+	}
+	if pk.location != nil && pk.location.String() == pk.TimeZone {
+		return pk.location
+	}
+	// TimeZone diubah setelah spec di-load
+	if location, err := time.LoadLocation(pk.TimeZone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// completeTime fills in what layout leaves out of t: the date for a time
+// only field, the year for a field without one, picking the candidate
+// nearest to now.
+func completeTime(t time.Time, layout string, now time.Time) time.Time {
+	var candidates []time.Time
+	switch {
+	case !strings.Contains(layout, "01"):
+		for _, days := range []int{-1, 0, 1} {
+			d := now.AddDate(0, 0, days)
+			candidates = append(candidates, time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location()))
+		}
+	case !strings.Contains(layout, "06"):
+		for _, years := range []int{-1, 0, 1} {
+			candidates = append(candidates, time.Date(now.Year()+years, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location()))
+		}
+	default:
+		return t
+	}
+
+	nearest := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Sub(now).Abs() < nearest.Sub(now).Abs() {
+			nearest = c
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,56 @@
+package iso8583
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompleteTime(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		value  string
+		layout string
+		now    time.Time
+		want   time.Time
+	}{
+		{"MMDD read on new year's day", "1231", "0102", date(2026, 1, 1, 0, 30), date(2025, 12, 31, 0, 0)},
+		{"MMDD ahead of a late december clock", "0101", "0102", date(2025, 12, 31, 23, 50), date(2026, 1, 1, 0, 0)},
+		{"MMDDhhmmss across the year end", "1231235900", "0102150405", date(2026, 1, 1, 0, 1), date(2025, 12, 31, 23, 59)},
+		{"MMDDhhmmss ahead of the year end", "0101000100", "0102150405", date(2025, 12, 31, 23, 59), date(2026, 1, 1, 0, 1)},
+		{"MMDD same year", "0615", "0102", date(2026, 6, 20, 12, 0), date(2026, 6, 15, 0, 0)},
+		{"hhmmss before midnight", "235900", "150405", date(2026, 1, 1, 0, 1), date(2025, 12, 31, 23, 59)},
+		{"hhmmss after midnight", "000100", "150405", date(2025, 12, 31, 23, 59), date(2026, 1, 1, 0, 1)},
+		{"YYMMDD kept as is", "251231", "060102", date(2026, 1, 1, 0, 1), date(2025, 12, 31, 0, 0)},
+	}
+	for _, tt := range tests {
+		parsed, err := time.ParseInLocation(tt.layout, tt.value, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := completeTime(parsed, tt.layout, tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: completeTime = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	iso := NewDefaultPackager().NewMessage()
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := iso.SetTransmissionTime(now); err != nil {
+		t.Fatal(err)
+	}
+	got, err := iso.GetTime(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(now) {
+		t.Errorf("DE 7 = %v, want %v", got, now)
+	}
+
+	if err := iso.SetTime(39, now); err == nil {
+		t.Error("DE 39 accepted as a date/time field")
+	}
+}
@@ -0,0 +1,119 @@
+package iso8583
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/randyardiansyah25/go-iso8583/iso8583/card"
+)
+
+// FieldError reports a problem with a single data element.
+type FieldError struct {
+	Field int
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %d: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError collects every FieldError found in a message.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors, so errors.Is finds e.g. ErrInvalidMTI.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+// contentTypeCheckers maps a spec ContentType to the rule its values must
+// satisfy. Content types without an entry are not checked.
+var contentTypeCheckers = map[string]func(c byte) bool{
+	"n":   isNumeric,
+	"a":   func(c byte) bool { return isAlpha(c) || c == ' ' },
+	"an":  func(c byte) bool { return isAlpha(c) || isNumeric(c) || c == ' ' },
+	"ns":  func(c byte) bool { return isPrintable(c) && !isAlpha(c) },
+	"ans": isPrintable,
+	"z":   func(c byte) bool { return isNumeric(c) || strings.IndexByte("=D;?", c) >= 0 },
+	"b":   func(c byte) bool { return true },
+}
+
+func isNumeric(c byte) bool   { return c >= '0' && c <= '9' }
+func isAlpha(c byte) bool     { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isPrintable(c byte) bool { return c >= 0x20 && c <= 0x7e }
+
+// Validate implements ISO8583Object. It checks every set field against the
+// ContentType declared in the spec and returns a *ValidationError listing
+// all violations.
+func (p *isoObject) Validate() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.validate()
+}
+
+func (p *isoObject) validate() error {
+	var problems []*FieldError
+	for k, value := range p.isoElement {
+		// MTI (0) diperiksa checkMTI, bitmap (1) dihitung saat compose
+		fs, ok := p.packager.spec(k)
+		if !ok || k <= 1 {
+			continue
+		}
+		if err := fs.checkValue(value); err != nil {
+			problems = append(problems, &FieldError{Field: k, Err: err})
+		}
+	}
+	// Diurutkan di sini saja supaya message yang valid tidak perlu sort
+	if len(problems) > 1 {
+		sort.Slice(problems, func(i, j int) bool { return problems[i].Field < problems[j].Field })
+	}
+	if err := p.checkMTI(true); err != nil {
+		problems = append(problems, err)
+	}
+	if err := p.checkPAN(); err != nil {
+		problems = append(problems, err)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// checkPAN validates DE 2 when the spec asks for it.
+func (p *isoObject) checkPAN() *FieldError {
+	pan, ok := p.isoElement[2]
+	if !p.packager.ValidatePAN || !ok {
+		return nil
+	}
+	if err := card.ValidatePAN(pan); err != nil {
+		return &FieldError{Field: 2, Err: err}
+	}
+	return nil
+}
+
+// Warnings implements ISO8583Object. It returns the problems tolerated by the
+// last lenient ComposeMessage or ParseWithOptions.
+func (p *isoObject) Warnings() []error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.warnings
+}
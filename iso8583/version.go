@@ -0,0 +1,112 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ISO 8583 versions, as carried by the first MTI digit.
+const (
+	Version1987 = "1987"
+	Version1993 = "1993"
+	Version2003 = "2003"
+)
+
+// ErrMTIVersion is returned for an MTI whose version digit does not match
+// the Version of the spec.
+var ErrMTIVersion = errors.New("MTI version does not match the spec version")
+
+var versionDigits = map[string]byte{
+	Version1987: '0',
+	Version1993: '1',
+	Version2003: '2',
+}
+
+// MTIVersion returns the version of mti from its first digit, or "" when
+// the digit denotes no known version (e.g. 9 for private use).
+func MTIVersion(mti string) string {
+	if len(mti) != 4 {
+		return ""
+	}
+	for version, digit := range versionDigits {
+		if mti[0] == digit {
+			return version
+		}
+	}
+	return ""
+}
+
+// mti1987To1993 maps the 1987 MTIs whose 1993 counterpart differs in more
+// than the version digit: 1993 has no reversal request, only the reversal
+// advice, and moved network management to 1804/1814.
+var mti1987To1993 = map[string]string{
+	"0400": "420",
+	"0401": "421",
+	"0410": "430",
+	"0800": "804",
+	"0801": "805",
+	"0810": "814",
+}
+
+// ConvertMTI maps mti to its counterpart in version, e.g. 0200 -> 1200 and
+// 0800 -> 1804 for Version1993. Going back to Version1987, network
+// management MTIs map to 08x0 and reversal advices stay advices.
+func ConvertMTI(mti, version string) (string, error) {
+	digit, ok := versionDigits[version]
+	if !ok {
+		return "", fmt.Errorf("unknown ISO 8583 version %q", version)
+	}
+	from := MTIVersion(mti)
+	if from == "" {
+		return "", fmt.Errorf("MTI %q has no known version", mti)
+	}
+
+	rest := mti[1:]
+	switch {
+	case from == Version1987 && version != Version1987:
+		if mapped, ok := mti1987To1993[mti]; ok {
+			rest = mapped
+		}
+	case from != Version1987 && version == Version1987:
+		// Kode fungsi 1993 untuk network management (18x4) kembali ke 08x0
+		if rest[0] == '8' && rest[2] >= '4' {
+			rest = rest[:2] + string(rest[2]-4)
+		}
+	}
+	return string(digit) + rest, nil
+}
+
+// checkMTI validates the MTI version digit when the spec has a Version, and
+// the MTI format when format is set.
+func (p *isoObject) checkMTI(format bool) *FieldError {
+	mti, ok := p.isoElement[0]
+	if !ok {
+		return nil
+	}
+	if format {
+		if err := ValidateMTI(mti); err != nil {
+			return &FieldError{Field: 0, Err: err}
+		}
+	}
+	if p.packager.Version != "" && MTIVersion(mti) != p.packager.Version {
+		return &FieldError{Field: 0, Err: ErrMTIVersion}
+	}
+	return nil
+}
+
+// isNetworkMTI reports whether mti is of the network management class
+// (08xx, 18xx, 28xx), whatever its version.
+func isNetworkMTI(mti string) bool {
+	return len(mti) == 4 && mti[1] == '8'
+}
+
+// approvalCode returns the approved DE 39 for a message: response code "00"
+// up to 1987 and action code "000" from 1993 on.
+func approvalCode(mti string) string {
+	switch MTIVersion(mti) {
+	case Version1993, Version2003:
+		return "000"
+	default:
+		return "00"
+	}
+}
@@ -0,0 +1,283 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Direction tells whether a logged message was received or sent.
+type Direction string
+
+const (
+	Inbound  Direction = "in"
+	Outbound Direction = "out"
+)
+
+// DefaultSensitiveFields are masked by a WireLog unless configured
+// otherwise: PAN, track 2, track 1 and PIN block.
+var DefaultSensitiveFields = []int{2, 35, 45, 52}
+
+// WireLog records every message as one JSON line holding the raw bytes (hex)
+// and the parsed fields, with the sensitive fields masked in both. Raw bytes
+// that cannot be parsed are never written, only their length, so an
+// unparseable message cannot leak card data.
+//
+// Set it on TCPIso8583Engine.WireLog or ISOClient.WireLog. A WireLog is safe
+// for concurrent use.
+type WireLog struct {
+	// Packager parses raw messages logged without their parsed form. nil
+	// uses the spec loaded by Load.
+	Packager *Packager
+	// SensitiveFields are masked in the record. Defaults to
+	// DefaultSensitiveFields.
+	SensitiveFields []int
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWireLog creates a WireLog writing to w, e.g. a *RotatingFile.
+func NewWireLog(w io.Writer) *WireLog {
+	return &WireLog{w: w}
+}
+
+type wireRecord struct {
+	Time      time.Time         `json:"time"`
+	Direction Direction         `json:"direction"`
+	Peer      string            `json:"peer,omitempty"`
+	Length    int               `json:"length"`
+	Raw       string            `json:"raw,omitempty"`
+	MTI       string            `json:"mti,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Log records one message exchanged with peer. iso is the parsed form of raw;
+// when nil, raw is parsed with Packager.
+func (l *WireLog) Log(dir Direction, peer string, raw []byte, iso ISO8583Object) error {
+	record := wireRecord{
+		Time:      time.Now().UTC(),
+		Direction: dir,
+		Peer:      peer,
+		Length:    len(raw),
+	}
+
+	if iso == nil {
+		var err error
+		if iso, err = l.parse(raw); err != nil {
+			record.Error = err.Error()
+		}
+	}
+	if iso != nil {
+		record.Raw = hex.EncodeToString(l.maskRaw(raw, iso))
+		record.MTI = iso.GetMTI()
+		record.Fields = make(map[string]string)
+		for _, field := range iso.Fields() {
+			value := iso.GetField(field)
+			if l.sensitive(field) {
+				value = maskValue(field, value, DefaultMaskOptions.Prefix, DefaultMaskOptions.Suffix)
+			} else if cfg, ok := fieldConfig(iso, field); ok && cfg.ContentType == "b" {
+				value = hex.EncodeToString([]byte(value))
+			}
+			record.Fields[strconv.Itoa(field)] = value
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(line)
+	return err
+}
+
+func (l *WireLog) parse(raw []byte) (ISO8583Object, error) {
+	var (
+		iso ISO8583Object
+		err error
+	)
+	if l.Packager != nil {
+		iso = l.Packager.NewMessage()
+	} else if iso, err = NewISO8583(); err != nil {
+		return nil, err
+	}
+	if err := iso.ParseBytes(raw); err != nil {
+		return nil, err
+	}
+	return iso, nil
+}
+
+func (l *WireLog) sensitive(field int) bool {
+	fields := l.SensitiveFields
+	if fields == nil {
+		fields = DefaultSensitiveFields
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// maskRaw returns a copy of raw with the sensitive fields masked, see
+// maskWire.
+func (l *WireLog) maskRaw(raw []byte, iso ISO8583Object) []byte {
+	fields := l.SensitiveFields
+	if fields == nil {
+		fields = DefaultSensitiveFields
+	}
+	return maskWire(raw, iso, fields)
+}
+
+// maskWire returns a copy of raw with the wire bytes of every field in fields
+// replaced by their masked form. Every occurrence is replaced, which also
+// catches a PAN repeated in another field. Longer values go first so track
+// data is masked before the PAN inside it.
+func maskWire(raw []byte, iso ISO8583Object, fields []int) []byte {
+	type replacement struct{ wire, masked []byte }
+	var replacements []replacement
+	for _, field := range iso.Fields() {
+		if !slices.Contains(fields, field) {
+			continue
+		}
+		cfg, ok := fieldConfig(iso, field)
+		value := iso.GetField(field)
+		if !ok || value == "" {
+			continue
+		}
+
+		r := replacement{wire: cfg.wireValue([]byte(value))}
+		if cfg.Encoding == EncodingHex || cfg.ContentType == "b" || cfg.codec != nil {
+			r.masked = bytes.Repeat([]byte("*"), len(r.wire))
+		} else {
+			r.masked = cfg.encodeText([]byte(maskValue(field, value, DefaultMaskOptions.Prefix, DefaultMaskOptions.Suffix)))
+		}
+		replacements = append(replacements, r)
+	}
+	sort.Slice(replacements, func(i, j int) bool {
+		return len(replacements[i].wire) > len(replacements[j].wire)
+	})
+
+	masked := bytes.Clone(raw)
+	for _, r := range replacements {
+		masked = bytes.ReplaceAll(masked, r.wire, r.masked)
+	}
+	return masked
+}
+
+// fieldConfig returns the spec of field for messages created by this
+// package.
+func fieldConfig(iso ISO8583Object, field int) (FieldConfig, bool) {
+	p, ok := iso.(*isoObject)
+	if !ok {
+		return FieldConfig{}, false
+	}
+	cfg, ok := p.packager.fields[field]
+	return cfg, ok
+}
+
+// RotatingFile is an io.WriteCloser appending to Path and rotating it to
+// Path.1, Path.2, ... once it would grow beyond MaxSize bytes. At most
+// MaxBackups rotated files are kept.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64
+	MaxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotatingFile creates a RotatingFile; the file is opened on first write.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) *RotatingFile {
+	return &RotatingFile{Path: path, MaxSize: maxSize, MaxBackups: maxBackups}
+}
+
+// Write implements io.Writer.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.MaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	if r.MaxBackups <= 0 {
+		if err := os.Remove(r.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+
+	_ = os.Remove(r.backup(r.MaxBackups))
+	for i := r.MaxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(r.backup(i), r.backup(i+1))
+	}
+	if err := os.Rename(r.Path, r.backup(1)); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *RotatingFile) backup(n int) string {
+	return r.Path + "." + strconv.Itoa(n)
+}
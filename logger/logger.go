@@ -1,3 +1,9 @@
+// Package logger forwards log lines to glg through a channel drained by
+// Watcher.
+//
+// Deprecated: the iso8583 engine and client log through log/slog and no
+// longer start Watcher. Callers still using this package must run Watcher
+// themselves, otherwise Error and Log block.
 package logger
 
 import "github.com/kpango/glg"